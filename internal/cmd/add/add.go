@@ -0,0 +1,74 @@
+package add
+
+import (
+	"fmt"
+	"github.com/MeysamBavi/group-expense-manager/internal/log"
+	"github.com/MeysamBavi/group-expense-manager/internal/storage"
+	"github.com/spf13/cobra"
+	"path"
+	"strings"
+)
+
+var (
+	overwrite bool
+	update    bool
+)
+
+func AddToRoot(root *cobra.Command) {
+	addCmd := newAddCommand()
+	root.AddCommand(addCmd)
+}
+
+func newAddCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "add",
+		Short: "Adds a new expense or transaction to an existing spreadsheet",
+	}
+
+	cmd.PersistentFlags().BoolVarP(
+		&overwrite,
+		"overwrite",
+		"r",
+		false,
+		"if set, overwrites the existing file instead of creating a new copy",
+	)
+
+	cmd.PersistentFlags().BoolVarP(
+		&update,
+		"update",
+		"u",
+		false,
+		"if set, updates the debt matrix and settlements too",
+	)
+
+	cmd.AddCommand(newExpenseCommand())
+	cmd.AddCommand(newTransactionCommand())
+
+	return cmd
+}
+
+// save updates the debt matrix if needed and saves the store.
+func save(s storage.Store, fileName, message string) {
+	if update {
+		err := storage.Update(s)
+		if err != nil {
+			log.FatalError(err)
+		}
+	}
+
+	fileName = getOutputFileName(fileName)
+	err := s.SaveAs(fileName)
+	if err != nil {
+		log.FatalError(err)
+	}
+
+	fmt.Printf("%s and saved to %s\n", message, fileName)
+}
+
+func getOutputFileName(fileName string) string {
+	if overwrite {
+		return fileName
+	}
+	ext := path.Ext(fileName)
+	return strings.TrimSuffix(fileName, ext) + "-updated" + ext
+}
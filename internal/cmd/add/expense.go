@@ -0,0 +1,107 @@
+package add
+
+import (
+	"errors"
+	"fmt"
+	"github.com/MeysamBavi/group-expense-manager/internal/log"
+	"github.com/MeysamBavi/group-expense-manager/internal/model"
+	"github.com/MeysamBavi/group-expense-manager/internal/storage"
+	"github.com/spf13/cobra"
+	"strings"
+	"time"
+)
+
+var (
+	expenseTime     string
+	expenseTitle    string
+	expensePayer    string
+	expenseAmount   string
+	expenseCurrency string
+	expenseMode     string
+	expenseSplit    string
+	expensePaid     string
+)
+
+func newExpenseCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "expense file-name",
+		Short: "Adds a new expense to an existing spreadsheet",
+		Long: `Adds a new expense to the first empty row of the expenses sheet.
+The split flag lists the members who share the expense, with their share weights, exact amounts or percentages based on the split mode.
+A member without a value gets a share weight of 1. If the split flag is not set, the expense is split equally between the active members.`,
+		Example: `add expense my-sheet.xlsx --title dinner --payer alice --amount 90 --split alice=2,bob,carol
+add expense my-sheet.xlsx --title taxi --amount 30 --paid alice=10,bob=20 --mode exact --split bob=12,carol=18`,
+		Args: func(_ *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return errors.New("exactly one argument is required as file name")
+			}
+			if expenseTitle == "" {
+				return errors.New("title is required")
+			}
+			if expensePayer == "" && expensePaid == "" {
+				return errors.New("either payer or paid is required")
+			}
+			return nil
+		},
+		Run: runExpense,
+	}
+
+	cmd.Flags().StringVarP(&expenseTime, "time", "t", "", "time of the expense (default now)")
+	cmd.Flags().StringVar(&expenseTitle, "title", "", "title of the expense")
+	cmd.Flags().StringVarP(&expensePayer, "payer", "p", "", "name of the member who paid the expense")
+	cmd.Flags().StringVarP(&expenseAmount, "amount", "a", "", "total amount of the expense")
+	cobra.CheckErr(cmd.MarkFlagRequired("amount"))
+	cmd.Flags().StringVarP(&expenseCurrency, "currency", "c", "", "currency of the amount (default the base currency)")
+	cmd.Flags().StringVarP(
+		&expenseMode,
+		"mode",
+		"m",
+		string(model.SplitByWeight),
+		"split mode of the expense. valid values are "+strings.Join(getValidSplitModes(), ", "),
+	)
+	cmd.Flags().StringVarP(&expenseSplit, "split", "s", "", "comma separated list of members and their shares, like alice=2,bob")
+	cmd.Flags().StringVar(&expensePaid, "paid", "", "comma separated list of payers and their paid amounts, like alice=60,bob=30")
+
+	return cmd
+}
+
+func runExpense(_ *cobra.Command, args []string) {
+	fileName := args[0]
+
+	s, err := storage.Open(fileName)
+	if err != nil {
+		log.FatalError(err)
+	}
+
+	expense, err := storage.ParseExpense(s, storage.ExpenseFields{
+		Time:     expenseTime,
+		Title:    expenseTitle,
+		Payer:    expensePayer,
+		Amount:   expenseAmount,
+		Currency: expenseCurrency,
+		Mode:     expenseMode,
+		Split:    expenseSplit,
+		Paid:     expensePaid,
+	})
+	if err != nil {
+		log.FatalError(err)
+	}
+	if expense.Time == nil {
+		expense.Time = model.TimeOfGregorian(time.Now())
+	}
+
+	err = s.AddExpense(expense)
+	if err != nil {
+		log.FatalError(err)
+	}
+
+	save(s, fileName, fmt.Sprintf("Added expense %q", expense.Title))
+}
+
+func getValidSplitModes() []string {
+	var result []string
+	for _, mode := range model.SplitModes {
+		result = append(result, string(mode))
+	}
+	return result
+}
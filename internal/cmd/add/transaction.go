@@ -0,0 +1,76 @@
+package add
+
+import (
+	"errors"
+	"fmt"
+	"github.com/MeysamBavi/group-expense-manager/internal/log"
+	"github.com/MeysamBavi/group-expense-manager/internal/model"
+	"github.com/MeysamBavi/group-expense-manager/internal/storage"
+	"github.com/spf13/cobra"
+	"time"
+)
+
+var (
+	transactionTime     string
+	transactionReceiver string
+	transactionPayer    string
+	transactionAmount   string
+	transactionCurrency string
+)
+
+func newTransactionCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "transaction file-name",
+		Short:   "Adds a new transaction to an existing spreadsheet",
+		Long:    "Adds a new transaction to the first empty row of the transactions sheet.",
+		Example: "add transaction my-sheet.xlsx --receiver alice --payer bob --amount 20",
+		Args: func(_ *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return errors.New("exactly one argument is required as file name")
+			}
+			if transactionReceiver == "" || transactionPayer == "" {
+				return errors.New("receiver and payer are required")
+			}
+			return nil
+		},
+		Run: runTransaction,
+	}
+
+	cmd.Flags().StringVarP(&transactionTime, "time", "t", "", "time of the transaction (default now)")
+	cmd.Flags().StringVar(&transactionReceiver, "receiver", "", "name of the member who received the money")
+	cmd.Flags().StringVarP(&transactionPayer, "payer", "p", "", "name of the member who paid the money")
+	cmd.Flags().StringVarP(&transactionAmount, "amount", "a", "", "amount of the transaction")
+	cobra.CheckErr(cmd.MarkFlagRequired("amount"))
+	cmd.Flags().StringVarP(&transactionCurrency, "currency", "c", "", "currency of the amount (default the base currency)")
+
+	return cmd
+}
+
+func runTransaction(_ *cobra.Command, args []string) {
+	fileName := args[0]
+
+	s, err := storage.Open(fileName)
+	if err != nil {
+		log.FatalError(err)
+	}
+
+	transaction, err := storage.ParseTransaction(storage.TransactionFields{
+		Time:     transactionTime,
+		Receiver: transactionReceiver,
+		Payer:    transactionPayer,
+		Amount:   transactionAmount,
+		Currency: transactionCurrency,
+	})
+	if err != nil {
+		log.FatalError(err)
+	}
+	if transaction.Time == nil {
+		transaction.Time = model.TimeOfGregorian(time.Now())
+	}
+	err = s.AddTransaction(transaction)
+	if err != nil {
+		log.FatalError(err)
+	}
+
+	save(s, fileName, fmt.Sprintf("Added transaction of %s from %q to %q", transaction.Amount, transaction.PayerName, transaction.ReceiverName))
+}
@@ -0,0 +1,94 @@
+package balance
+
+import (
+	"errors"
+	"github.com/MeysamBavi/group-expense-manager/internal/log"
+	"github.com/MeysamBavi/group-expense-manager/internal/report"
+	"github.com/MeysamBavi/group-expense-manager/internal/storage"
+	"github.com/spf13/cobra"
+	"os"
+	"strings"
+)
+
+var (
+	output  string
+	explain bool
+)
+
+func AddToRoot(root *cobra.Command) {
+	cmd := newBalanceCommand()
+	root.AddCommand(cmd)
+}
+
+func newBalanceCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "balance file-name",
+		Short: "Prints the balances, debts and settlements",
+		Long: `Prints each member's net balance, the debt matrix and the settlements, calculated from the expenses, transactions and base state.
+A positive balance means the member gets money back and a negative one means they owe money. The file is not changed.`,
+		Example: `balance my-sheet.xlsx
+balance my-sheet.xlsx --output json`,
+		Args: func(_ *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return errors.New("exactly one argument is required as file name")
+			}
+			return nil
+		},
+		Run: run,
+	}
+
+	cmd.Flags().StringVarP(
+		&output,
+		"output",
+		"o",
+		string(report.Table),
+		"output format. valid values are "+strings.Join(getValidFormats(), ", "),
+	)
+
+	cmd.Flags().BoolVarP(
+		&explain,
+		"explain",
+		"e",
+		false,
+		"if set, shows the balances before settling and how each settlement changes them",
+	)
+
+	return cmd
+}
+
+func run(_ *cobra.Command, args []string) {
+	fileName := args[0]
+	format, err := report.ParseFormat(output, report.Formats)
+	if err != nil {
+		log.FatalError(err)
+	}
+
+	s, err := storage.Open(fileName)
+	if err != nil {
+		log.FatalError(err)
+	}
+	err = storage.Update(s)
+	if err != nil {
+		log.FatalError(err)
+	}
+
+	r := report.Of(s, fileName)
+	if explain {
+		err = r.Explain(s)
+		if err != nil {
+			log.FatalError(err)
+		}
+	}
+	err = r.Write(os.Stdout, format)
+	if err != nil {
+		log.FatalError(err)
+	}
+}
+
+func getValidFormats() []string {
+	var result []string
+	for _, format := range report.Formats {
+		result = append(result, string(format))
+	}
+	return result
+}
@@ -10,6 +10,8 @@ import (
 	"github.com/MeysamBavi/group-expense-manager/internal/sheet"
 	"github.com/MeysamBavi/group-expense-manager/internal/sheet/store"
 	"github.com/MeysamBavi/group-expense-manager/internal/sheet/style"
+	"github.com/MeysamBavi/group-expense-manager/internal/storage"
+	"github.com/MeysamBavi/group-expense-manager/pkg/ledger"
 	"github.com/spf13/cobra"
 	"os"
 	"regexp"
@@ -17,9 +19,13 @@ import (
 )
 
 var (
-	membersFile string
-	outputFile  string
-	theme       string
+	membersFile    string
+	outputFile     string
+	theme          string
+	fractionDigits int
+	rounding       string
+	baseCurrency   string
+	settle         string
 )
 
 var (
@@ -61,7 +67,7 @@ Format of every row in the csv file should be <name>,<cardNumber>`,
 		"output",
 		"o",
 		"expense-manager.xlsx",
-		"specifies the output file name and path",
+		"specifies the output file name and path. the data is kept as JSON if the extension is .json, as an SQLite database if it is .sqlite, .sqlite3 or .db, otherwise as a spreadsheet",
 	)
 
 	cmd.Flags().StringVarP(
@@ -72,6 +78,36 @@ Format of every row in the csv file should be <name>,<cardNumber>`,
 		"specifies the color theme of the spreadsheet. valid values are "+strings.Join(getValidThemes(), ", "),
 	)
 
+	cmd.Flags().IntVarP(
+		&fractionDigits,
+		"fraction-digits",
+		"d",
+		0,
+		"specifies the number of digits after the decimal point in amounts, e.g. 2 for cents",
+	)
+
+	cmd.Flags().StringVar(
+		&rounding,
+		"rounding",
+		string(model.LargestRemainder),
+		"specifies how the amount of an expense is rounded when split into shares. valid values are "+strings.Join(getValidRoundingPolicies(), ", "),
+	)
+
+	cmd.Flags().StringVarP(
+		&baseCurrency,
+		"currency",
+		"c",
+		"",
+		"specifies the base currency of the group, e.g. USD. the debts are calculated in this currency",
+	)
+
+	cmd.Flags().StringVar(
+		&settle,
+		"settle",
+		string(ledger.GreedySettlement),
+		"specifies how the settlements are chosen. valid values are "+strings.Join(getValidSettlementModes(), ", "),
+	)
+
 	return cmd
 }
 
@@ -92,8 +128,27 @@ func run(_ *cobra.Command, _ []string) {
 		log.FatalError(errors.New("number of members should be more than 1"))
 	}
 
-	manager := sheet.NewManager(members, getTheme())
-	err := manager.SaveAs(outputFile)
+	settings := sheet.DefaultSettings()
+	settings.FractionDigits = fractionDigits
+	if err := settings.Validate(); err != nil {
+		log.FatalError(err)
+	}
+	policy, err := model.ParseRoundingPolicy(rounding)
+	if err != nil {
+		log.FatalError(err)
+	}
+	settings.Rounding = policy
+	settings.BaseCurrency = strings.ToUpper(strings.TrimSpace(baseCurrency))
+	settings.Settlement, err = ledger.ParseSettlementMode(settle)
+	if err != nil {
+		log.FatalError(err)
+	}
+
+	s, err := storage.New(outputFile, members, getTheme(), settings)
+	if err != nil {
+		log.FatalError(err)
+	}
+	err = s.SaveAs(outputFile)
 	if err != nil {
 		log.FatalError(err)
 	}
@@ -173,6 +228,22 @@ func getValidThemes() []string {
 	return themes
 }
 
+func getValidRoundingPolicies() []string {
+	policies := make([]string, 0, len(model.RoundingPolicies))
+	for _, policy := range model.RoundingPolicies {
+		policies = append(policies, string(policy))
+	}
+	return policies
+}
+
+func getValidSettlementModes() []string {
+	modes := make([]string, 0, len(ledger.SettlementModes))
+	for _, mode := range ledger.SettlementModes {
+		modes = append(modes, string(mode))
+	}
+	return modes
+}
+
 func getTheme() *style.Theme {
 	theme, ok := validThemes[theme]
 	if !ok {
@@ -0,0 +1,59 @@
+package export
+
+import (
+	"errors"
+	"fmt"
+	"github.com/MeysamBavi/group-expense-manager/internal/log"
+	"github.com/MeysamBavi/group-expense-manager/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+var (
+	csvDir string
+)
+
+func newCSVCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "csv file-name",
+		Short: "Exports the data of a group as CSV files",
+		Long: `Writes members.csv, expenses.csv, transactions.csv, debt_matrix.csv, settlements.csv and base_state.csv in the output directory.
+expenses.csv has one row for every share of every expense, with the share amount in the base currency. The debt matrix and settlements are calculated again.`,
+		Example: "export csv my-sheet.xlsx --out data/",
+		Args: func(_ *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return errors.New("exactly one argument is required as file name")
+			}
+			return nil
+		},
+		Run: runCSV,
+	}
+
+	cmd.Flags().StringVarP(
+		&csvDir,
+		"out",
+		"o",
+		".",
+		"specifies the output directory. it is created if it does not exist",
+	)
+
+	return cmd
+}
+
+func runCSV(_ *cobra.Command, args []string) {
+	s, err := storage.Open(args[0])
+	if err != nil {
+		log.FatalError(err)
+	}
+	err = storage.Update(s)
+	if err != nil {
+		log.FatalError(err)
+	}
+
+	fileNames, err := storage.ExportCSV(s, csvDir)
+	if err != nil {
+		log.FatalError(err)
+	}
+	for _, fileName := range fileNames {
+		fmt.Printf("Exported %s\n", fileName)
+	}
+}
@@ -0,0 +1,76 @@
+package export
+
+import (
+	"errors"
+	"fmt"
+	"github.com/MeysamBavi/group-expense-manager/internal/log"
+	"github.com/MeysamBavi/group-expense-manager/internal/sheet/style"
+	"github.com/MeysamBavi/group-expense-manager/internal/storage"
+	"github.com/spf13/cobra"
+	"os"
+	"path"
+	"strings"
+)
+
+var (
+	xlsxFile string
+)
+
+func AddToRoot(root *cobra.Command) {
+	cmd := newExportCommand()
+	root.AddCommand(cmd)
+}
+
+func newExportCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "export file-name",
+		Short:   "Exports the data of a group to other formats",
+		Long:    "Writes the data of a group, kept in any format, as a spreadsheet. The debt matrix and settlements are calculated again. Use the csv subcommand to export CSV files instead.",
+		Example: "export my-group.sqlite --xlsx my-sheet.xlsx",
+		Args: func(_ *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return errors.New("exactly one argument is required as file name")
+			}
+			if xlsxFile == "" {
+				return errors.New("the output spreadsheet is required")
+			}
+			if !strings.EqualFold(path.Ext(xlsxFile), ".xlsx") {
+				return errors.New("the output spreadsheet should have the .xlsx extension")
+			}
+			return nil
+		},
+		Run: run,
+	}
+
+	cmd.AddCommand(newCSVCommand())
+
+	cmd.Flags().StringVar(
+		&xlsxFile,
+		"xlsx",
+		"",
+		"specifies the output spreadsheet name and path. it should not exist",
+	)
+
+	return cmd
+}
+
+func run(_ *cobra.Command, args []string) {
+	if _, err := os.Stat(xlsxFile); err == nil {
+		log.FatalError(fmt.Errorf("file %q already exists", xlsxFile))
+	}
+
+	source, err := storage.Open(args[0])
+	if err != nil {
+		log.FatalError(err)
+	}
+	target, err := storage.Copy(source, xlsxFile, style.BlueTheme())
+	if err != nil {
+		log.FatalError(err)
+	}
+	err = target.SaveAs(xlsxFile)
+	if err != nil {
+		log.FatalError(err)
+	}
+
+	fmt.Printf("Exported to %s\n", xlsxFile)
+}
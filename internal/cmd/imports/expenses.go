@@ -0,0 +1,31 @@
+package imports
+
+import (
+	"github.com/MeysamBavi/group-expense-manager/internal/storage"
+	"github.com/spf13/cobra"
+	"strings"
+)
+
+func newExpensesCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "expenses file-name csv-file-name",
+		Short: "Imports expenses from a CSV file",
+		Long: `Appends the expenses of a CSV file to the expenses sheet.
+The first row of the file is a header naming some of these columns in any order: ` + strings.Join(storage.ExpenseColumns, ", ") + `.
+The columns take the same values as the flags of "add expense"; title and amount are required and an empty time means an unspecified time.
+Every row is validated and all the invalid rows are reported with their line numbers.`,
+		Example: `import expenses my-sheet.xlsx expenses.csv
+
+with expenses.csv like:
+time,title,payer,amount,split
+2023-05-01,dinner,alice,90,"alice=2,bob,carol"`,
+		Args: validateArgs,
+		Run: func(_ *cobra.Command, args []string) {
+			runImport(args, "expenses", storage.ImportExpensesCSV)
+		},
+	}
+
+	addRecordsFlags(cmd)
+
+	return cmd
+}
@@ -0,0 +1,111 @@
+package imports
+
+import (
+	"errors"
+	"fmt"
+	"github.com/MeysamBavi/group-expense-manager/internal/log"
+	"github.com/MeysamBavi/group-expense-manager/internal/storage"
+	"github.com/spf13/cobra"
+	"path"
+	"strings"
+)
+
+var (
+	overwrite   bool
+	update      bool
+	skipInvalid bool
+)
+
+func AddToRoot(root *cobra.Command) {
+	importCmd := newImportCommand()
+	root.AddCommand(importCmd)
+}
+
+func newImportCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "import",
+		Short: "Imports expenses or transactions from a CSV file",
+	}
+
+	cmd.AddCommand(newExpensesCommand())
+	cmd.AddCommand(newTransactionsCommand())
+	cmd.AddCommand(newSplitwiseCommand())
+
+	return cmd
+}
+
+// addRecordsFlags adds the flags of the commands that import records into an existing spreadsheet.
+func addRecordsFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVarP(
+		&overwrite,
+		"overwrite",
+		"r",
+		false,
+		"if set, overwrites the existing file instead of creating a new copy",
+	)
+
+	cmd.Flags().BoolVarP(
+		&update,
+		"update",
+		"u",
+		false,
+		"if set, updates the debt matrix and settlements too",
+	)
+
+	cmd.Flags().BoolVar(
+		&skipInvalid,
+		"skip-invalid",
+		false,
+		"if set, imports the valid rows even if some rows are invalid",
+	)
+}
+
+func validateArgs(_ *cobra.Command, args []string) error {
+	if len(args) != 2 {
+		return errors.New("exactly two arguments are required as file name and csv file name")
+	}
+	return nil
+}
+
+// runImport opens a store, imports a CSV file into it and saves it. If some rows are invalid, all of them are reported
+// and nothing is saved, unless skipInvalid is set.
+func runImport(args []string, records string, importCSV func(s storage.Store, csvFileName string) (int, error)) {
+	fileName, csvFileName := args[0], args[1]
+
+	s, err := storage.Open(fileName)
+	if err != nil {
+		log.FatalError(err)
+	}
+
+	count, err := importCSV(s, csvFileName)
+	var validationErr *log.ValidationError
+	if err != nil && (!skipInvalid || !errors.As(err, &validationErr)) {
+		log.FatalError(err)
+	}
+	if err != nil {
+		log.Error(fmt.Errorf("skipped invalid rows: %w", err))
+	}
+
+	if update {
+		err = storage.Update(s)
+		if err != nil {
+			log.FatalError(err)
+		}
+	}
+
+	fileName = getOutputFileName(fileName)
+	err = s.SaveAs(fileName)
+	if err != nil {
+		log.FatalError(err)
+	}
+
+	fmt.Printf("Imported %d %s and saved to %s\n", count, records, fileName)
+}
+
+func getOutputFileName(fileName string) string {
+	if overwrite {
+		return fileName
+	}
+	ext := path.Ext(fileName)
+	return strings.TrimSuffix(fileName, ext) + "-updated" + ext
+}
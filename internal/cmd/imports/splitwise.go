@@ -0,0 +1,63 @@
+package imports
+
+import (
+	"errors"
+	"fmt"
+	"github.com/MeysamBavi/group-expense-manager/internal/log"
+	"github.com/MeysamBavi/group-expense-manager/internal/sheet/style"
+	"github.com/MeysamBavi/group-expense-manager/internal/storage"
+	"github.com/spf13/cobra"
+	"os"
+)
+
+var (
+	splitwiseOutputFile string
+)
+
+func newSplitwiseCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "splitwise csv-file-name",
+		Short: "Creates a new spreadsheet from a Splitwise CSV export",
+		Long: `Creates a new spreadsheet with the history of a Splitwise group, exported as CSV from Splitwise.
+The members are the member columns of the export. Payment rows become transactions and the other rows become expenses with exact shares, so that every member's balance changes the same way it does in Splitwise.
+The base currency and the fraction digits are taken from the export; exports with several currencies are not supported.
+If the export ends with a total balance row, the balances of the debt matrix are checked against it.`,
+		Example: "import splitwise my-group_2023-05-31_export.csv -o my-sheet.xlsx",
+		Args: func(_ *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return errors.New("exactly one argument is required as csv file name")
+			}
+			return nil
+		},
+		Run: runSplitwise,
+	}
+
+	cmd.Flags().StringVarP(
+		&splitwiseOutputFile,
+		"output",
+		"o",
+		"expense-manager.xlsx",
+		"specifies the output file name and path. it should not exist",
+	)
+
+	return cmd
+}
+
+func runSplitwise(_ *cobra.Command, args []string) {
+	if _, err := os.Stat(splitwiseOutputFile); err == nil {
+		log.FatalError(fmt.Errorf("file %q already exists", splitwiseOutputFile))
+	}
+
+	s, err := storage.ImportSplitwise(args[0], splitwiseOutputFile, style.BlueTheme())
+	if err != nil {
+		log.FatalError(err)
+	}
+	err = s.SaveAs(splitwiseOutputFile)
+	if err != nil {
+		log.FatalError(err)
+	}
+
+	l := s.Ledger()
+	fmt.Printf("Imported %d members, %d expenses and %d transactions and saved to %s\n",
+		len(l.Members), len(l.Expenses), len(l.Transactions), splitwiseOutputFile)
+}
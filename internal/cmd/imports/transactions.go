@@ -0,0 +1,31 @@
+package imports
+
+import (
+	"github.com/MeysamBavi/group-expense-manager/internal/storage"
+	"github.com/spf13/cobra"
+	"strings"
+)
+
+func newTransactionsCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "transactions file-name csv-file-name",
+		Short: "Imports transactions from a CSV file",
+		Long: `Appends the transactions of a CSV file to the transactions sheet.
+The first row of the file is a header naming some of these columns in any order: ` + strings.Join(storage.TransactionColumns, ", ") + `.
+The columns take the same values as the flags of "add transaction"; receiver, payer and amount are required and an empty time means an unspecified time.
+Every row is validated and all the invalid rows are reported with their line numbers.`,
+		Example: `import transactions my-sheet.xlsx transactions.csv
+
+with transactions.csv like:
+time,receiver,payer,amount
+2023-05-02,alice,bob,20`,
+		Args: validateArgs,
+		Run: func(_ *cobra.Command, args []string) {
+			runImport(args, "transactions", storage.ImportTransactionsCSV)
+		},
+	}
+
+	addRecordsFlags(cmd)
+
+	return cmd
+}
@@ -0,0 +1,61 @@
+package member
+
+import (
+	"errors"
+	"fmt"
+	"github.com/MeysamBavi/group-expense-manager/internal/log"
+	"github.com/MeysamBavi/group-expense-manager/internal/model"
+	"github.com/MeysamBavi/group-expense-manager/internal/sheet"
+	"github.com/spf13/cobra"
+	"strings"
+)
+
+func newAddCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "add file-name member-name [card-number]",
+		Short: "Adds a new member to an existing spreadsheet",
+		Long: `Adds a new member to an existing spreadsheet. The new member is added to the members table, the expenses sheet, the base state and the debt matrix.
+The share weight of the new member in all the existing expenses is set to zero.`,
+		Example: `member add my-sheet.xlsx alice "6037-9975-1234-5678"`,
+		Args: func(_ *cobra.Command, args []string) error {
+			if len(args) < 2 {
+				return errors.New("file name and member name are required")
+			}
+			if len(args) > 3 {
+				return errors.New("too many arguments")
+			}
+			return nil
+		},
+		Run: runAdd,
+	}
+
+	return cmd
+}
+
+func runAdd(_ *cobra.Command, args []string) {
+	fileName := args[0]
+	member := &model.Member{
+		Name: strings.TrimSpace(args[1]),
+	}
+	if len(args) > 2 {
+		member.CardNumber = strings.TrimSpace(args[2])
+	}
+
+	manager, err := sheet.LoadManager(fileName)
+	if err != nil {
+		log.FatalError(err)
+	}
+
+	err = manager.AddMember(member)
+	if err != nil {
+		log.FatalError(err)
+	}
+
+	fileName = getOutputFileName(fileName)
+	err = manager.SaveAs(fileName)
+	if err != nil {
+		log.FatalError(err)
+	}
+
+	fmt.Printf("Added %q and saved to %s\n", member.Name, fileName)
+}
@@ -0,0 +1,49 @@
+package member
+
+import (
+	"errors"
+	"fmt"
+	"github.com/MeysamBavi/group-expense-manager/internal/log"
+	"github.com/MeysamBavi/group-expense-manager/internal/sheet"
+	"github.com/spf13/cobra"
+)
+
+func newDeactivateCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "deactivate file-name member-name",
+		Short: "Deactivates a member without removing their history",
+		Long: `Deactivates a member without removing their history. The member's share columns in the expenses sheet are hidden and their share weight in the dummy row is set to zero.
+The member's status can be changed back to active in the members sheet.`,
+		Example: "member deactivate my-sheet.xlsx alice",
+		Args: func(_ *cobra.Command, args []string) error {
+			if len(args) != 2 {
+				return errors.New("file name and member name are required")
+			}
+			return nil
+		},
+		Run: runDeactivate,
+	}
+
+	return cmd
+}
+
+func runDeactivate(_ *cobra.Command, args []string) {
+	fileName := args[0]
+	manager, err := sheet.LoadManager(fileName)
+	if err != nil {
+		log.FatalError(err)
+	}
+
+	err = manager.DeactivateMember(args[1])
+	if err != nil {
+		log.FatalError(err)
+	}
+
+	fileName = getOutputFileName(fileName)
+	err = manager.SaveAs(fileName)
+	if err != nil {
+		log.FatalError(err)
+	}
+
+	fmt.Printf("Deactivated %q and saved to %s\n", args[1], fileName)
+}
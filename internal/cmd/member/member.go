@@ -0,0 +1,46 @@
+package member
+
+import (
+	"github.com/spf13/cobra"
+	"path"
+	"strings"
+)
+
+var (
+	overwrite bool
+)
+
+func AddToRoot(root *cobra.Command) {
+	memberCmd := newMemberCommand()
+	root.AddCommand(memberCmd)
+}
+
+func newMemberCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "member",
+		Short: "Manages the members of an existing spreadsheet",
+	}
+
+	cmd.PersistentFlags().BoolVarP(
+		&overwrite,
+		"overwrite",
+		"r",
+		false,
+		"if set, overwrites the existing file instead of creating a new copy",
+	)
+
+	cmd.AddCommand(newAddCommand())
+	cmd.AddCommand(newRemoveCommand())
+	cmd.AddCommand(newDeactivateCommand())
+	cmd.AddCommand(newRenameCommand())
+
+	return cmd
+}
+
+func getOutputFileName(fileName string) string {
+	if overwrite {
+		return fileName
+	}
+	ext := path.Ext(fileName)
+	return strings.TrimSuffix(fileName, ext) + "-updated" + ext
+}
@@ -0,0 +1,62 @@
+package member
+
+import (
+	"errors"
+	"fmt"
+	"github.com/MeysamBavi/group-expense-manager/internal/log"
+	"github.com/MeysamBavi/group-expense-manager/internal/sheet"
+	"github.com/spf13/cobra"
+)
+
+var (
+	transferTo string
+)
+
+func newRemoveCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "remove file-name member-name",
+		Short: "Removes a member from an existing spreadsheet",
+		Long: `Removes a member from an existing spreadsheet. The member's share columns are removed from all the expenses.
+Without the --transfer-to flag, the member should have a zero net balance and no expenses, transactions or debts in the base state; Deactivate a member to keep their history instead.
+With the --transfer-to flag, the other member takes over the member's balance and their part in all the records: they become the payer of the member's expenses and transactions, and the member's shares and debts are added to theirs. Nobody else's net balance changes.`,
+		Example: "member remove my-sheet.xlsx alice --transfer-to bob",
+		Args: func(_ *cobra.Command, args []string) error {
+			if len(args) != 2 {
+				return errors.New("file name and member name are required")
+			}
+			return nil
+		},
+		Run: runRemove,
+	}
+
+	cmd.Flags().StringVarP(
+		&transferTo,
+		"transfer-to",
+		"t",
+		"",
+		"specifies the member who takes over the balance and the records of the removed member",
+	)
+
+	return cmd
+}
+
+func runRemove(_ *cobra.Command, args []string) {
+	fileName := args[0]
+	manager, err := sheet.LoadManager(fileName)
+	if err != nil {
+		log.FatalError(err)
+	}
+
+	err = manager.RemoveMember(args[1], transferTo)
+	if err != nil {
+		log.FatalError(err)
+	}
+
+	fileName = getOutputFileName(fileName)
+	err = manager.SaveAs(fileName)
+	if err != nil {
+		log.FatalError(err)
+	}
+
+	fmt.Printf("Removed %q and saved to %s\n", args[1], fileName)
+}
@@ -0,0 +1,51 @@
+package member
+
+import (
+	"errors"
+	"fmt"
+	"github.com/MeysamBavi/group-expense-manager/internal/log"
+	"github.com/MeysamBavi/group-expense-manager/internal/sheet"
+	"github.com/spf13/cobra"
+	"strings"
+)
+
+func newRenameCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rename file-name old-name new-name",
+		Short: "Renames a member in all the sheets",
+		Long: `Renames a member in all the sheets, including the members table, the expenses header, payers and receivers of expenses and transactions, the base state, the debt matrix and the settlements.
+Names are case-insensitive, so the new name should not be used by another member regardless of its case.`,
+		Example: "member rename my-sheet.xlsx alice Alicia",
+		Args: func(_ *cobra.Command, args []string) error {
+			if len(args) != 3 {
+				return errors.New("file name, old name and new name are required")
+			}
+			return nil
+		},
+		Run: runRename,
+	}
+
+	return cmd
+}
+
+func runRename(_ *cobra.Command, args []string) {
+	fileName := args[0]
+	oldName, newName := args[1], strings.TrimSpace(args[2])
+	manager, err := sheet.LoadManager(fileName)
+	if err != nil {
+		log.FatalError(err)
+	}
+
+	err = manager.RenameMember(oldName, newName)
+	if err != nil {
+		log.FatalError(err)
+	}
+
+	fileName = getOutputFileName(fileName)
+	err = manager.SaveAs(fileName)
+	if err != nil {
+		log.FatalError(err)
+	}
+
+	fmt.Printf("Renamed %q to %q and saved to %s\n", oldName, newName, fileName)
+}
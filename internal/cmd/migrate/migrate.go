@@ -0,0 +1,74 @@
+package migrate
+
+import (
+	"errors"
+	"fmt"
+	"github.com/MeysamBavi/group-expense-manager/internal/log"
+	"github.com/MeysamBavi/group-expense-manager/internal/sheet/style"
+	"github.com/MeysamBavi/group-expense-manager/internal/storage"
+	"github.com/spf13/cobra"
+	"os"
+)
+
+var (
+	targetFile string
+)
+
+func AddToRoot(root *cobra.Command) {
+	cmd := newMigrateCommand()
+	root.AddCommand(cmd)
+}
+
+func newMigrateCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "migrate file-name",
+		Short: "Copies the data of a group to a file of another format",
+		Long: `Copies the members, settings, rates, expenses, transactions and base state of a group to a new file and calculates the debts.
+The format of the new file is chosen by its extension: .sqlite, .sqlite3 or .db for an SQLite database, .json for a JSON file and .xlsx for a spreadsheet.`,
+		Example: "migrate --to my-group.sqlite my-sheet.xlsx",
+		Args: func(_ *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return errors.New("exactly one argument is required as file name")
+			}
+			if targetFile == "" {
+				return errors.New("the file to migrate to is required")
+			}
+			return nil
+		},
+		Run: run,
+	}
+
+	cmd.Flags().StringVar(
+		&targetFile,
+		"to",
+		"",
+		"specifies the new file name and path. it should not exist",
+	)
+
+	return cmd
+}
+
+func run(_ *cobra.Command, args []string) {
+	fileName := args[0]
+
+	if _, err := os.Stat(targetFile); err == nil {
+		log.FatalError(fmt.Errorf("file %q already exists", targetFile))
+	}
+
+	source, err := storage.Open(fileName)
+	if err != nil {
+		log.FatalError(err)
+	}
+	target, err := storage.Copy(source, targetFile, style.BlueTheme())
+	if err != nil {
+		log.FatalError(err)
+	}
+	err = target.SaveAs(targetFile)
+	if err != nil {
+		log.FatalError(err)
+	}
+
+	l := target.Ledger()
+	fmt.Printf("Migrated %d members, %d expenses and %d transactions to %s\n",
+		len(l.Members), len(l.Expenses), len(l.Transactions), targetFile)
+}
@@ -0,0 +1,104 @@
+package period
+
+import (
+	"errors"
+	"fmt"
+	"github.com/MeysamBavi/group-expense-manager/internal/log"
+	"github.com/MeysamBavi/group-expense-manager/internal/model"
+	"github.com/MeysamBavi/group-expense-manager/internal/sheet"
+	"github.com/MeysamBavi/group-expense-manager/internal/sheet/style"
+	"github.com/MeysamBavi/group-expense-manager/internal/storage"
+	"github.com/spf13/cobra"
+	"os"
+	"path"
+	"strings"
+	"time"
+)
+
+var (
+	outputFile string
+	overwrite  bool
+)
+
+func AddToRoot(root *cobra.Command) {
+	cmd := newClosePeriodCommand()
+	root.AddCommand(cmd)
+}
+
+func newClosePeriodCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "close-period file-name",
+		Short: "Closes the books and starts a new file for the next period",
+		Long: `Calculates the debts and writes them as the base state of a new file with the same members, settings and rates, but no expenses or transactions.
+Both files are stamped with the time of closing in their metadata: the end of the closed period and the start of the next one, along with the name of the other file.
+Unless --overwrite is set, the closed period is saved as a new copy and the next period names the copy.`,
+		Example: "close-period my-sheet.xlsx --out next.xlsx --overwrite",
+		Args: func(_ *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return errors.New("exactly one argument is required as file name")
+			}
+			if outputFile == "" {
+				return errors.New("the file of the next period is required")
+			}
+			return nil
+		},
+		Run: run,
+	}
+
+	cmd.Flags().StringVarP(
+		&outputFile,
+		"out",
+		"o",
+		"",
+		"specifies the file name and path of the next period. it should not exist",
+	)
+
+	cmd.Flags().BoolVarP(
+		&overwrite,
+		"overwrite",
+		"r",
+		false,
+		"if set, overwrites the closed file instead of creating a new copy",
+	)
+
+	return cmd
+}
+
+func run(_ *cobra.Command, args []string) {
+	fileName := args[0]
+
+	if _, err := os.Stat(outputFile); err == nil {
+		log.FatalError(fmt.Errorf("file %q already exists", outputFile))
+	}
+
+	s, err := storage.Open(fileName)
+	if err != nil {
+		log.FatalError(err)
+	}
+	theme := style.BlueTheme()
+	if manager, ok := s.(*sheet.Manager); ok {
+		theme = manager.Theme()
+	}
+
+	// the next period names the file that the closed period is saved as
+	if !overwrite {
+		ext := path.Ext(fileName)
+		fileName = strings.TrimSuffix(fileName, ext) + "-updated" + ext
+	}
+	next, err := storage.ClosePeriod(s, fileName, outputFile, theme, model.TimeOfGregorian(time.Now()))
+	if err != nil {
+		log.FatalError(err)
+	}
+	err = next.SaveAs(outputFile)
+	if err != nil {
+		log.FatalError(err)
+	}
+
+	err = s.SaveAs(fileName)
+	if err != nil {
+		log.FatalError(err)
+	}
+
+	fmt.Printf("Closed the period at %s and saved to %s\n", s.Settings().PeriodEnd, fileName)
+	fmt.Printf("Started the next period with the debts as its base state and saved to %s\n", outputFile)
+}
@@ -2,9 +2,19 @@ package cmd
 
 import (
 	"fmt"
+	"github.com/MeysamBavi/group-expense-manager/internal/cmd/add"
+	"github.com/MeysamBavi/group-expense-manager/internal/cmd/balance"
 	"github.com/MeysamBavi/group-expense-manager/internal/cmd/create"
+	"github.com/MeysamBavi/group-expense-manager/internal/cmd/export"
+	"github.com/MeysamBavi/group-expense-manager/internal/cmd/imports"
+	"github.com/MeysamBavi/group-expense-manager/internal/cmd/member"
 	"github.com/MeysamBavi/group-expense-manager/internal/cmd/message"
+	"github.com/MeysamBavi/group-expense-manager/internal/cmd/migrate"
+	"github.com/MeysamBavi/group-expense-manager/internal/cmd/period"
+	"github.com/MeysamBavi/group-expense-manager/internal/cmd/settle"
+	"github.com/MeysamBavi/group-expense-manager/internal/cmd/statement"
 	"github.com/MeysamBavi/group-expense-manager/internal/cmd/update"
+	"github.com/MeysamBavi/group-expense-manager/internal/cmd/validate"
 	"github.com/spf13/cobra"
 	"os"
 )
@@ -19,6 +29,16 @@ func init() {
 	message.AddToRoot(rootCmd)
 	create.AddToRoot(rootCmd)
 	update.AddToRoot(rootCmd)
+	member.AddToRoot(rootCmd)
+	add.AddToRoot(rootCmd)
+	validate.AddToRoot(rootCmd)
+	migrate.AddToRoot(rootCmd)
+	export.AddToRoot(rootCmd)
+	imports.AddToRoot(rootCmd)
+	balance.AddToRoot(rootCmd)
+	statement.AddToRoot(rootCmd)
+	settle.AddToRoot(rootCmd)
+	period.AddToRoot(rootCmd)
 }
 
 func Execute() {
@@ -0,0 +1,150 @@
+package settle
+
+import (
+	"errors"
+	"fmt"
+	"github.com/MeysamBavi/group-expense-manager/internal/log"
+	"github.com/MeysamBavi/group-expense-manager/internal/model"
+	"github.com/MeysamBavi/group-expense-manager/internal/storage"
+	"github.com/spf13/cobra"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	all       bool
+	pick      string
+	dryRun    bool
+	overwrite bool
+)
+
+func AddToRoot(root *cobra.Command) {
+	cmd := newSettleCommand()
+	root.AddCommand(cmd)
+}
+
+func newSettleCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "settle file-name",
+		Short: "Records settlements as transactions",
+		Long: "Adds the chosen settlements to the transactions with the current time and updates the debt matrix. " +
+			"The settlements are numbered from 1 in the order of the settlements sheet, after the debts are updated.",
+		Example: "settle my-sheet.xlsx --pick 2,3",
+		Args: func(_ *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return errors.New("exactly one argument is required as file name")
+			}
+			if all == (pick != "") {
+				return errors.New("exactly one of --all and --pick is required")
+			}
+			return nil
+		},
+		Run: run,
+	}
+
+	cmd.Flags().BoolVarP(
+		&all,
+		"all",
+		"a",
+		false,
+		"records all the settlements",
+	)
+
+	cmd.Flags().StringVarP(
+		&pick,
+		"pick",
+		"p",
+		"",
+		"comma separated numbers of the settlements to record, e.g. 2,3",
+	)
+
+	cmd.Flags().BoolVarP(
+		&dryRun,
+		"dry-run",
+		"n",
+		false,
+		"if set, only prints the transactions that would be added, without writing any file",
+	)
+
+	cmd.Flags().BoolVarP(
+		&overwrite,
+		"overwrite",
+		"r",
+		false,
+		"if set, overwrites the existing file instead of creating a new copy",
+	)
+
+	return cmd
+}
+
+func run(_ *cobra.Command, args []string) {
+	fileName := args[0]
+	numbers, err := parseNumbers(pick)
+	if err != nil {
+		log.FatalError(err)
+	}
+
+	s, err := storage.Open(fileName)
+	if err != nil {
+		log.FatalError(err)
+	}
+
+	now := model.TimeOfGregorian(time.Now())
+	if dryRun {
+		transactions, err := storage.SettlementTransactions(s, numbers, now)
+		if err != nil {
+			log.FatalError(err)
+		}
+		printTransactions("Would add", transactions)
+		return
+	}
+
+	transactions, err := storage.Settle(s, numbers, now)
+	if err != nil {
+		log.FatalError(err)
+	}
+	if !overwrite {
+		ext := path.Ext(fileName)
+		fileName = strings.TrimSuffix(fileName, ext) + "-updated" + ext
+	}
+	err = s.SaveAs(fileName)
+	if err != nil {
+		log.FatalError(err)
+	}
+	printTransactions("Added", transactions)
+	fmt.Printf("Updated debt matrix and saved to %s\n", fileName)
+}
+
+// parseNumbers parses the comma separated numbers of --pick. Repeated numbers are not allowed, since each settlement
+// can only be paid once.
+func parseNumbers(value string) ([]int, error) {
+	if value == "" {
+		return nil, nil
+	}
+	var numbers []int
+	seen := make(map[int]bool)
+	for _, part := range strings.Split(value, ",") {
+		number, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil {
+			return nil, fmt.Errorf("invalid settlement number %q", part)
+		}
+		if seen[number] {
+			return nil, fmt.Errorf("settlement number %d is repeated", number)
+		}
+		seen[number] = true
+		numbers = append(numbers, number)
+	}
+	return numbers, nil
+}
+
+func printTransactions(verb string, transactions []*model.Transaction) {
+	if len(transactions) == 0 {
+		fmt.Println("There is nothing to settle")
+		return
+	}
+	for _, transaction := range transactions {
+		fmt.Printf("%s transaction of %s from %q to %q\n", verb, transaction.Amount, transaction.PayerName, transaction.ReceiverName)
+	}
+}
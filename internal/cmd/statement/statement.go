@@ -0,0 +1,129 @@
+package statement
+
+import (
+	"errors"
+	"fmt"
+	"github.com/MeysamBavi/group-expense-manager/internal/log"
+	"github.com/MeysamBavi/group-expense-manager/internal/report"
+	"github.com/MeysamBavi/group-expense-manager/internal/sheet"
+	"github.com/MeysamBavi/group-expense-manager/internal/storage"
+	"github.com/MeysamBavi/group-expense-manager/pkg/ledger"
+	"github.com/spf13/cobra"
+	"os"
+	"path"
+	"strings"
+)
+
+var (
+	output     string
+	writeSheet bool
+	overwrite  bool
+)
+
+func AddToRoot(root *cobra.Command) {
+	cmd := newStatementCommand()
+	root.AddCommand(cmd)
+}
+
+func newStatementCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "statement file-name member-name",
+		Short: "Prints the statement of a member",
+		Long: `Prints every expense that the member paid or had a share in, every transaction they sent or received and the base state, with a running balance.
+Credit is what the others owe the member because of a record and debit is what the member owes the others. The last balance is the member's net balance used by the settlements.
+With the sheet flag, the statement is also written in a sheet of the spreadsheet named after the member.`,
+		Example: `statement my-sheet.xlsx alice
+statement my-sheet.xlsx alice --output csv > alice.csv
+statement my-sheet.xlsx alice --sheet --overwrite`,
+		Args: func(_ *cobra.Command, args []string) error {
+			if len(args) != 2 {
+				return errors.New("exactly two arguments are required as file name and member name")
+			}
+			return nil
+		},
+		Run: run,
+	}
+
+	cmd.Flags().StringVarP(
+		&output,
+		"output",
+		"o",
+		string(report.Text),
+		"output format. valid values are "+strings.Join(getValidFormats(), ", "),
+	)
+
+	cmd.Flags().BoolVar(
+		&writeSheet,
+		"sheet",
+		false,
+		"if set, writes the statement in a sheet of the spreadsheet too",
+	)
+
+	cmd.Flags().BoolVarP(
+		&overwrite,
+		"overwrite",
+		"r",
+		false,
+		"if set with the sheet flag, overwrites the existing file instead of creating a new copy",
+	)
+
+	return cmd
+}
+
+func run(_ *cobra.Command, args []string) {
+	fileName, memberName := args[0], args[1]
+	format, err := report.ParseFormat(output, report.StatementFormats)
+	if err != nil {
+		log.FatalError(err)
+	}
+
+	s, err := storage.Open(fileName)
+	if err != nil {
+		log.FatalError(err)
+	}
+	manager, isSpreadsheet := s.(*sheet.Manager)
+	if writeSheet && !isSpreadsheet {
+		log.FatalError(errors.New("statement sheets can only be written in spreadsheets"))
+	}
+
+	l := s.Ledger()
+	memberIndex := l.IndexOf(memberName)
+	if memberIndex == -1 {
+		log.FatalError(fmt.Errorf("found no member with name %q", memberName))
+	}
+	memberName = l.Members[memberIndex].Name
+	entries, err := ledger.ComputeStatement(l, memberIndex)
+	if err != nil {
+		log.FatalError(err)
+	}
+
+	err = report.StatementOf(s, fileName, memberName, entries).Write(os.Stdout, format)
+	if err != nil {
+		log.FatalError(err)
+	}
+
+	if !writeSheet {
+		return
+	}
+	err = manager.WriteStatement(memberName, entries)
+	if err != nil {
+		log.FatalError(err)
+	}
+	if !overwrite {
+		ext := path.Ext(fileName)
+		fileName = strings.TrimSuffix(fileName, ext) + "-updated" + ext
+	}
+	err = manager.SaveAs(fileName)
+	if err != nil {
+		log.FatalError(err)
+	}
+	fmt.Fprintf(os.Stderr, "Wrote the statement of %s and saved to %s\n", memberName, fileName)
+}
+
+func getValidFormats() []string {
+	var result []string
+	for _, format := range report.StatementFormats {
+		result = append(result, string(format))
+	}
+	return result
+}
@@ -4,8 +4,12 @@ import (
 	"errors"
 	"fmt"
 	"github.com/MeysamBavi/group-expense-manager/internal/log"
+	"github.com/MeysamBavi/group-expense-manager/internal/report"
 	"github.com/MeysamBavi/group-expense-manager/internal/sheet"
+	"github.com/MeysamBavi/group-expense-manager/internal/storage"
+	"github.com/MeysamBavi/group-expense-manager/pkg/ledger"
 	"github.com/spf13/cobra"
+	"os"
 	"path"
 	"strings"
 )
@@ -14,6 +18,9 @@ var (
 	overwrite bool
 	shortLog  bool
 	longLog   bool
+	output    string
+	explain   bool
+	settle    string
 )
 
 func AddToRoot(root *cobra.Command) {
@@ -30,6 +37,9 @@ func newUpdateCommand() *cobra.Command {
 			if len(args) < 1 {
 				return errors.New("no arguments passed as file name")
 			}
+			if format, _ := report.ParseFormat(output, report.Formats); format == report.JSON && (shortLog || longLog) {
+				return errors.New("json output cannot be combined with logs")
+			}
 			return nil
 		},
 		Run: run,
@@ -59,30 +69,110 @@ func newUpdateCommand() *cobra.Command {
 		"logs loaded data in a long format including expenses and transactions",
 	)
 
+	cmd.Flags().StringVarP(
+		&output,
+		"output",
+		"o",
+		string(report.Text),
+		"format of the printed balances, debts and settlements. valid values are "+strings.Join(getValidFormats(), ", "),
+	)
+
+	cmd.Flags().BoolVarP(
+		&explain,
+		"explain",
+		"e",
+		false,
+		"if set, shows how each settlement changes the balances, and writes it in the settlement steps sheet of a spreadsheet",
+	)
+
+	cmd.Flags().StringVar(
+		&settle,
+		"settle",
+		"",
+		"changes how the settlements of the file are chosen. valid values are "+strings.Join(getValidSettlementModes(), ", ")+
+			". the mode is saved in the file and used by the next updates",
+	)
+
 	return cmd
 }
 
 func run(_ *cobra.Command, args []string) {
 	fileName := args[0]
-	manager, err := sheet.LoadManager(fileName)
+	format, err := report.ParseFormat(output, report.Formats)
 	if err != nil {
 		log.FatalError(err)
 	}
 
-	manager.UpdateDebtors()
+	s, err := storage.Open(fileName)
+	if err != nil {
+		log.FatalError(err)
+	}
+
+	if settle != "" {
+		mode, err := ledger.ParseSettlementMode(settle)
+		if err != nil {
+			log.FatalError(err)
+		}
+		settings := *s.Settings()
+		settings.Settlement = mode
+		err = s.SetSettings(&settings)
+		if err != nil {
+			log.FatalError(err)
+		}
+	}
+
+	err = storage.Update(s)
+	if err != nil {
+		log.FatalError(err)
+	}
+	if manager, ok := s.(*sheet.Manager); ok && explain {
+		err = manager.WriteSettlementSteps()
+		if err != nil {
+			log.FatalError(err)
+		}
+	}
 	if shortLog {
-		manager.PrintData(true)
+		storage.PrintData(s, true)
 	} else if longLog {
-		manager.PrintData(false)
+		storage.PrintData(s, false)
 	}
 	if !overwrite {
 		ext := path.Ext(fileName)
 		fileName = strings.TrimSuffix(fileName, ext) + "-updated" + ext
 	}
-	err = manager.SaveAs(fileName)
+	err = s.SaveAs(fileName)
 	if err != nil {
 		log.FatalError(err)
 	}
 
-	fmt.Printf("Updated debt matrix and saved to %s\n", fileName)
+	r := report.Of(s, fileName)
+	if explain {
+		err = r.Explain(s)
+		if err != nil {
+			log.FatalError(err)
+		}
+	}
+	err = r.Write(os.Stdout, format)
+	if err != nil {
+		log.FatalError(err)
+	}
+	if format != report.JSON {
+		fmt.Printf("Updated debt matrix and saved to %s\n", fileName)
+	}
+}
+
+func getValidFormats() []string {
+	var result []string
+	for _, format := range report.Formats {
+		result = append(result, string(format))
+	}
+	return result
+}
+
+func getValidSettlementModes() []string {
+	var result []string
+	for _, mode := range ledger.SettlementModes {
+		result = append(result, string(mode))
+	}
+	return result
 }
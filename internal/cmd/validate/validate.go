@@ -0,0 +1,42 @@
+package validate
+
+import (
+	"errors"
+	"fmt"
+	"github.com/MeysamBavi/group-expense-manager/internal/storage"
+	"github.com/spf13/cobra"
+	"os"
+)
+
+func AddToRoot(root *cobra.Command) {
+	cmd := newValidateCommand()
+	root.AddCommand(cmd)
+}
+
+func newValidateCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "validate file-name",
+		Short: "Checks a spreadsheet, JSON file or SQLite database for invalid values",
+		Long:  "Checks the expenses, transactions, rates and base state of a spreadsheet, JSON file or SQLite database and reports all the invalid values. Nothing is written.",
+		Args: func(_ *cobra.Command, args []string) error {
+			if len(args) < 1 {
+				return errors.New("no arguments passed as file name")
+			}
+			return nil
+		},
+		Run: run,
+	}
+
+	return cmd
+}
+
+func run(_ *cobra.Command, args []string) {
+	fileName := args[0]
+	_, err := storage.Open(fileName)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Found no problems in %s\n", fileName)
+}
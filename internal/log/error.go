@@ -1,13 +1,46 @@
 package log
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// CellError is an error caused by the value of a cell.
+type CellError struct {
+	Err       error
+	SheetName string
+	CellName  string
+}
+
+func (e *CellError) Error() string {
+	return fmt.Sprintf("%v: in %q at %q", e.Err, e.SheetName, e.CellName)
+}
+
+func (e *CellError) Unwrap() error {
+	return e.Err
+}
+
+// SheetError is an error caused by a sheet, but not a specific cell.
+type SheetError struct {
+	Err       error
+	SheetName string
+}
+
+func (e *SheetError) Error() string {
+	return fmt.Sprintf("%v: in %q", e.Err, e.SheetName)
+}
+
+func (e *SheetError) Unwrap() error {
+	return e.Err
+}
 
 func CellErrorOf(err error, sheetName, cellName string) error {
 	if err == nil {
 		return nil
 	}
 
-	return fmt.Errorf("%w: in %q at %q", err, sheetName, cellName)
+	return &CellError{Err: err, SheetName: sheetName, CellName: cellName}
 }
 
 func SheetErrorOf(err error, sheetName string) error {
@@ -15,5 +48,89 @@ func SheetErrorOf(err error, sheetName string) error {
 		return nil
 	}
 
-	return fmt.Errorf("%w: in %q", err, sheetName)
+	return &SheetError{Err: err, SheetName: sheetName}
+}
+
+// ValidationError holds all the errors found in a file. Its message is a report of the errors grouped by sheet, or by
+// section in files that are not spreadsheets.
+type ValidationError struct {
+	Errors []error
+}
+
+func (e *ValidationError) Error() string {
+	var sheetNames []string
+	errorsBySheet := make(map[string][]string)
+	var others []string
+	for _, err := range e.Errors {
+		var cellErr *CellError
+		var sheetErr *SheetError
+		switch {
+		case errors.As(err, &cellErr):
+			if _, ok := errorsBySheet[cellErr.SheetName]; !ok {
+				sheetNames = append(sheetNames, cellErr.SheetName)
+			}
+			errorsBySheet[cellErr.SheetName] = append(errorsBySheet[cellErr.SheetName],
+				fmt.Sprintf("%s: %v", cellErr.CellName, cellErr.Err))
+		case errors.As(err, &sheetErr):
+			if _, ok := errorsBySheet[sheetErr.SheetName]; !ok {
+				sheetNames = append(sheetNames, sheetErr.SheetName)
+			}
+			errorsBySheet[sheetErr.SheetName] = append(errorsBySheet[sheetErr.SheetName], sheetErr.Err.Error())
+		default:
+			others = append(others, err.Error())
+		}
+	}
+
+	var sb strings.Builder
+	if len(e.Errors) == 1 {
+		sb.WriteString("found 1 problem in the file")
+	} else {
+		fmt.Fprintf(&sb, "found %d problems in the file", len(e.Errors))
+	}
+	for _, sheetName := range sheetNames {
+		fmt.Fprintf(&sb, "\n%q:", sheetName)
+		for _, message := range errorsBySheet[sheetName] {
+			fmt.Fprintf(&sb, "\n  %s", message)
+		}
+	}
+	for _, message := range others {
+		fmt.Fprintf(&sb, "\n%s", message)
+	}
+	return sb.String()
+}
+
+// Unwrap returns the errors, so that errors.Is and errors.As look for an error among them.
+func (e *ValidationError) Unwrap() []error {
+	return e.Errors
+}
+
+// ErrorList collects errors, so that all of them can be reported at once.
+type ErrorList struct {
+	errors []error
+}
+
+// Add appends an error to the list. Nil errors are ignored and the errors of a ValidationError are added one by one.
+func (l *ErrorList) Add(err error) {
+	if err == nil {
+		return
+	}
+	var validationErr *ValidationError
+	if errors.As(err, &validationErr) {
+		l.errors = append(l.errors, validationErr.Errors...)
+		return
+	}
+	l.errors = append(l.errors, err)
+}
+
+// Len returns the number of errors in the list.
+func (l *ErrorList) Len() int {
+	return len(l.errors)
+}
+
+// Err returns a ValidationError holding the errors, or nil if the list is empty.
+func (l *ErrorList) Err() error {
+	if len(l.errors) == 0 {
+		return nil
+	}
+	return &ValidationError{Errors: l.errors}
 }
@@ -0,0 +1,50 @@
+package log_test
+
+import (
+	"errors"
+	"github.com/MeysamBavi/group-expense-manager/internal/log"
+	assert2 "github.com/stretchr/testify/assert"
+	"io"
+	"testing"
+)
+
+func TestErrorList(t *testing.T) {
+	assert := assert2.New(t)
+
+	var errs log.ErrorList
+	assert.NoError(errs.Err())
+
+	errs.Add(nil)
+	errs.Add(log.CellErrorOf(errors.New("bad time"), "expenses", "A4"))
+	errs.Add(log.CellErrorOf(errors.New("bad amount"), "transactions", "D3"))
+	errs.Add(log.SheetErrorOf(errors.New("found no Payer column"), "expenses"))
+	errs.Add(errors.New("something else"))
+	assert.Equal(4, errs.Len())
+
+	var merged log.ErrorList
+	merged.Add(errs.Err())
+	merged.Add(log.CellErrorOf(errors.New("bad weight"), "expenses", "G5"))
+
+	err := merged.Err()
+	var validationErr *log.ValidationError
+	assert.ErrorAs(err, &validationErr)
+	assert.Len(validationErr.Errors, 5)
+	assert.Equal(`found 5 problems in the file
+"expenses":
+  A4: bad time
+  found no Payer column
+  G5: bad weight
+"transactions":
+  D3: bad amount
+something else`, err.Error())
+
+	// the errors of the list are found through the ValidationError
+	var cellErr *log.CellError
+	if assert.ErrorAs(err, &cellErr) {
+		assert.Equal("A4", cellErr.CellName)
+	}
+	var sheetErr *log.SheetError
+	assert.ErrorAs(err, &sheetErr)
+	merged.Add(log.CellErrorOf(io.ErrUnexpectedEOF, "rates", "B2"))
+	assert.ErrorIs(merged.Err(), io.ErrUnexpectedEOF)
+}
@@ -19,10 +19,6 @@ func FatalError(err error) {
 	log.Fatal(getLineInfo(0), err)
 }
 
-func FatalErrorByCaller(err error) {
-	log.Fatal(getLineInfo(1), err)
-}
-
 func getLineInfo(skipOffset int) string {
 	pc, fileName, lineNumber, ok := runtime.Caller(2 + skipOffset)
 	return formatLineInfo(fileName, runtime.FuncForPC(pc).Name(), lineNumber, ok)
@@ -1,6 +0,0 @@
-package model
-
-type Member struct {
-	Name       string
-	CardNumber string
-}
@@ -0,0 +1,74 @@
+// Package model gives the internal packages short names for the types and functions of the ledger package, where the
+// members, expenses and transactions of a group are defined.
+package model
+
+import (
+	"github.com/MeysamBavi/group-expense-manager/pkg/ledger"
+	"time"
+)
+
+type (
+	Amount         = ledger.Amount
+	Time           = ledger.Time
+	Member         = ledger.Member
+	Share          = ledger.Share
+	Expense        = ledger.Expense
+	Transaction    = ledger.Transaction
+	SplitMode      = ledger.SplitMode
+	RoundingPolicy = ledger.RoundingPolicy
+)
+
+const (
+	SplitByWeight     = ledger.SplitByWeight
+	SplitByExact      = ledger.SplitByExact
+	SplitByPercentage = ledger.SplitByPercentage
+
+	LargestRemainder = ledger.LargestRemainder
+	RoundHalfEven    = ledger.RoundHalfEven
+	PayerAbsorbs     = ledger.PayerAbsorbs
+)
+
+var (
+	SplitModes       = ledger.SplitModes
+	RoundingPolicies = ledger.RoundingPolicies
+)
+
+func AmountOf(a int64) Amount {
+	return ledger.AmountOf(a)
+}
+
+func AmountZero() Amount {
+	return ledger.AmountZero()
+}
+
+func ParseAmount(a string) (Amount, error) {
+	return ledger.ParseAmount(a)
+}
+
+func ParseTime(value string) (Time, error) {
+	return ledger.ParseTime(value)
+}
+
+func TimeOfGregorian(t time.Time) Time {
+	return ledger.TimeOfGregorian(t)
+}
+
+func ParseSplitMode(value string) (SplitMode, error) {
+	return ledger.ParseSplitMode(value)
+}
+
+func ParseShareWeight(weightStr string) (int, error) {
+	return ledger.ParseShareWeight(weightStr)
+}
+
+func ParseShareValue(valueStr string) (Amount, error) {
+	return ledger.ParseShareValue(valueStr)
+}
+
+func ParseRoundingPolicy(value string) (RoundingPolicy, error) {
+	return ledger.ParseRoundingPolicy(value)
+}
+
+func ParseMemberStatus(status string) (bool, error) {
+	return ledger.ParseMemberStatus(status)
+}
@@ -0,0 +1,224 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// Format is a way of writing a report.
+type Format string
+
+const (
+	// Text writes the report as sentences, like "bob owes alice 20".
+	Text Format = "text"
+	// Table writes the report as aligned tables.
+	Table Format = "table"
+	// JSON writes the report as an indented JSON object.
+	JSON Format = "json"
+	// CSV writes the report as a CSV table.
+	CSV Format = "csv"
+)
+
+// Formats are the formats of a Report and StatementFormats are the formats of a Statement.
+var (
+	Formats          = []Format{Text, Table, JSON}
+	StatementFormats = []Format{Text, CSV, JSON}
+)
+
+// ParseFormat parses the name of one of the given formats.
+func ParseFormat(value string, formats []Format) (Format, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	for _, format := range formats {
+		if value == string(format) {
+			return format, nil
+		}
+	}
+	return "", fmt.Errorf("invalid output format %q", value)
+}
+
+// Write writes the report in a format.
+func (r *Report) Write(w io.Writer, format Format) error {
+	switch format {
+	case JSON:
+		return r.writeJSON(w)
+	case Table:
+		return r.writeTable(w)
+	default:
+		return r.writeText(w)
+	}
+}
+
+func (r *Report) writeJSON(w io.Writer) error {
+	return writeJSON(w, r)
+}
+
+func (r *Report) writeText(w io.Writer) error {
+	var sb strings.Builder
+	sb.WriteString("Balances:\n")
+	for _, balance := range r.Balances {
+		fmt.Fprintf(&sb, "  %s\n", balanceSentence(balance.Member, balance.Amount, r.Currency))
+	}
+
+	sb.WriteString("Debts:\n")
+	for i, row := range r.DebtMatrix {
+		for j, amount := range row {
+			if i != j && !isZero(amount) {
+				fmt.Fprintf(&sb, "  %s owes %s %s\n", r.Members[i], r.Members[j], r.withCurrency(amount))
+			}
+		}
+	}
+
+	sb.WriteString("Settlements:\n")
+	for _, settlement := range r.Settlements {
+		fmt.Fprintf(&sb, "  %s pays %s %s\n", settlement.Payer, settlement.Receiver, r.withCurrency(settlement.Amount))
+	}
+	if len(r.Settlements) == 0 {
+		sb.WriteString("  nothing to settle\n")
+	}
+
+	if r.Explanation != nil {
+		sb.WriteString("Settlement steps:\n")
+		fmt.Fprintf(&sb, "  before settling: %s\n", r.balanceSentences(r.Explanation.Before))
+		for i, step := range r.Explanation.Steps {
+			fmt.Fprintf(&sb, "  %d. %s pays %s %s: %s\n", i+1, step.Payer, step.Receiver, r.withCurrency(step.Amount),
+				r.balanceSentences(step.Balances))
+		}
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func (r *Report) writeTable(w io.Writer) error {
+	var sb strings.Builder
+
+	amountHeader := "Balance"
+	if r.Currency != "" {
+		amountHeader += " (" + r.Currency + ")"
+	}
+	var rows [][]string
+	for _, balance := range r.Balances {
+		rows = append(rows, []string{balance.Member, balance.Amount})
+	}
+	writeTable(&sb, []string{"Member", amountHeader}, rows, 1)
+
+	sb.WriteString("\n")
+	rows = nil
+	for i, row := range r.DebtMatrix {
+		cells := []string{r.Members[i]}
+		for j, amount := range row {
+			if i == j {
+				amount = "-"
+			}
+			cells = append(cells, amount)
+		}
+		rows = append(rows, cells)
+	}
+	writeTable(&sb, append([]string{`Debtor \ Creditor`}, r.Members...), rows, 1)
+
+	sb.WriteString("\n")
+	rows = nil
+	for _, settlement := range r.Settlements {
+		rows = append(rows, []string{settlement.Payer, settlement.Receiver, settlement.Amount})
+	}
+	writeTable(&sb, []string{"Payer", "Receiver", "Amount"}, rows, 2)
+
+	if r.Explanation != nil {
+		sb.WriteString("\n")
+		rows = [][]string{append([]string{"before settling", "", "", ""}, r.Explanation.Before...)}
+		for i, step := range r.Explanation.Steps {
+			rows = append(rows, append([]string{fmt.Sprintf("%d", i+1), step.Payer, step.Receiver, step.Amount}, step.Balances...))
+		}
+		writeTable(&sb, append([]string{"Step", "Payer", "Receiver", "Amount"}, r.Members...), rows, 3)
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// balanceSentences describes the balances of all the members, in the order of the members.
+func (r *Report) balanceSentences(balances []string) string {
+	sentences := make([]string, len(balances))
+	for i, balance := range balances {
+		sentences[i] = balanceSentence(r.Members[i], balance, r.Currency)
+	}
+	return strings.Join(sentences, ", ")
+}
+
+func (r *Report) withCurrency(amount string) string {
+	return withCurrency(amount, r.Currency)
+}
+
+func withCurrency(amount, currency string) string {
+	if currency == "" {
+		return amount
+	}
+	return amount + " " + currency
+}
+
+// balanceSentence describes the net balance of a member, like "bob owes 20 USD".
+func balanceSentence(member, amount, currency string) string {
+	switch {
+	case isZero(amount):
+		return member + " is settled up"
+	case strings.HasPrefix(amount, "-"):
+		return member + " owes " + withCurrency(strings.TrimPrefix(amount, "-"), currency)
+	default:
+		return member + " gets back " + withCurrency(amount, currency)
+	}
+}
+
+func writeJSON(w io.Writer, v any) error {
+	content, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(content, '\n'))
+	return err
+}
+
+// writeTable writes a table with a header. The first nameColumns columns are aligned to the left and the others, which
+// hold amounts, to the right.
+func writeTable(sb *strings.Builder, header []string, rows [][]string, nameColumns int) {
+	widths := make([]int, len(header))
+	for _, row := range append([][]string{header}, rows...) {
+		for i, cell := range row {
+			if n := utf8.RuneCountInString(cell); n > widths[i] {
+				widths[i] = n
+			}
+		}
+	}
+
+	writeRow := func(row []string) {
+		for i, cell := range row {
+			padding := strings.Repeat(" ", widths[i]-utf8.RuneCountInString(cell))
+			if i > 0 {
+				sb.WriteString("  ")
+			}
+			if i < nameColumns {
+				sb.WriteString(cell + padding)
+			} else {
+				sb.WriteString(padding + cell)
+			}
+		}
+		sb.WriteString("\n")
+	}
+
+	writeRow(header)
+	separators := make([]string, len(header))
+	for i, width := range widths {
+		separators[i] = strings.Repeat("-", width)
+	}
+	writeRow(separators)
+	for _, row := range rows {
+		writeRow(row)
+	}
+}
+
+// isZero reports whether a formatted amount is zero.
+func isZero(amount string) bool {
+	return strings.Trim(amount, "-0.") == ""
+}
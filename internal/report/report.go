@@ -0,0 +1,118 @@
+// Package report summarizes the debts of a group for printing in a terminal or reading by other programs.
+package report
+
+import (
+	"github.com/MeysamBavi/group-expense-manager/internal/model"
+	"github.com/MeysamBavi/group-expense-manager/internal/storage"
+	"github.com/MeysamBavi/group-expense-manager/pkg/ledger"
+)
+
+// Report holds the balances, debts and settlements of a group. The amounts are formatted with the fraction digits of
+// the group and are in its base currency.
+type Report struct {
+	File     string    `json:"file"`
+	Currency string    `json:"currency"`
+	Members  []string  `json:"members"`
+	Balances []Balance `json:"balances"`
+	// DebtMatrix is the debt matrix of the group: DebtMatrix[i][j] is what member i owes member j.
+	DebtMatrix  [][]string   `json:"debtMatrix"`
+	Settlements []Settlement `json:"settlements"`
+	// Explanation is only set by Explain.
+	Explanation *Explanation `json:"explanation,omitempty"`
+}
+
+// Balance is the net balance of a member. A positive amount means the member should get money back, and a negative
+// amount means the member owes money.
+type Balance struct {
+	Member string `json:"member"`
+	Amount string `json:"amount"`
+}
+
+// Settlement is a transaction that settles the debts.
+type Settlement struct {
+	Payer    string `json:"payer"`
+	Receiver string `json:"receiver"`
+	Amount   string `json:"amount"`
+}
+
+// Explanation shows how the settlements clear the debts. The balances are in the order of the members, with the same
+// sign as the balances of a Report.
+type Explanation struct {
+	// Before holds the balances before settling.
+	Before []string `json:"before"`
+	Steps  []Step   `json:"steps"`
+}
+
+// Step is a settlement and the balances of the members after it.
+type Step struct {
+	Settlement
+	Balances []string `json:"balances"`
+}
+
+// Of returns the report of a store kept in a file. The debt matrix and the settlements are taken as they are in the
+// store, so the store should be updated first.
+func Of(s storage.Store, fileName string) *Report {
+	l := s.Ledger()
+	fractionDigits := s.Settings().FractionDigits
+	debtMatrix := s.DebtMatrix()
+
+	r := &Report{
+		File:        fileName,
+		Currency:    s.Settings().BaseCurrency,
+		Members:     []string{},
+		Balances:    []Balance{},
+		DebtMatrix:  [][]string{},
+		Settlements: []Settlement{},
+	}
+	balances := formatBalances(ledger.ComputeBalances(debtMatrix), fractionDigits)
+	for i, member := range l.Members {
+		r.Members = append(r.Members, member.Name)
+		r.Balances = append(r.Balances, Balance{Member: member.Name, Amount: balances[i]})
+	}
+	for _, row := range debtMatrix {
+		formatted := make([]string, len(row))
+		for j, amount := range row {
+			formatted[j] = amount.Format(fractionDigits)
+		}
+		r.DebtMatrix = append(r.DebtMatrix, formatted)
+	}
+	for _, settlement := range s.Settlements() {
+		r.Settlements = append(r.Settlements, Settlement{
+			Payer:    settlement.PayerName,
+			Receiver: settlement.ReceiverName,
+			Amount:   settlement.Amount.Format(fractionDigits),
+		})
+	}
+	return r
+}
+
+// Explain adds the explanation of the settlements of a store to the report. The store should be the one that the report
+// is of.
+func (r *Report) Explain(s storage.Store) error {
+	fractionDigits := s.Settings().FractionDigits
+	before, steps, err := ledger.ExplainSettlements(s.Ledger().Members, s.DebtMatrix(), s.Settlements())
+	if err != nil {
+		return err
+	}
+	r.Explanation = &Explanation{Before: formatBalances(before, fractionDigits), Steps: []Step{}}
+	for _, step := range steps {
+		r.Explanation.Steps = append(r.Explanation.Steps, Step{
+			Settlement: Settlement{
+				Payer:    step.Settlement.PayerName,
+				Receiver: step.Settlement.ReceiverName,
+				Amount:   step.Settlement.Amount.Format(fractionDigits),
+			},
+			Balances: formatBalances(step.Balances, fractionDigits),
+		})
+	}
+	return nil
+}
+
+// formatBalances formats balances of the ledger, which are positive for debtors, with the sign of the report.
+func formatBalances(balances []model.Amount, fractionDigits int) []string {
+	result := make([]string, len(balances))
+	for i, balance := range balances {
+		result[i] = balance.Negative().Format(fractionDigits)
+	}
+	return result
+}
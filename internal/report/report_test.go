@@ -0,0 +1,44 @@
+package report_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/MeysamBavi/group-expense-manager/internal/report"
+	"github.com/MeysamBavi/group-expense-manager/internal/storage/storagetest"
+	assert2 "github.com/stretchr/testify/assert"
+	"path/filepath"
+	"testing"
+)
+
+func TestReport(t *testing.T) {
+	assert := assert2.New(t)
+
+	r := report.Of(storagetest.Scenario(t, filepath.Join(t.TempDir(), "group.json")), "group.json")
+	assert.Equal([]report.Balance{
+		{Member: "alice", Amount: "29.16"},
+		{Member: "bob", Amount: "-8.33"},
+		{Member: "carol", Amount: "11.67"},
+		{Member: "dave", Amount: "-32.50"},
+	}, r.Balances)
+	assert.Equal("18.33", r.DebtMatrix[1][0])
+	assert.Len(r.Settlements, 3)
+
+	var buf bytes.Buffer
+	assert.NoError(r.Write(&buf, report.JSON))
+	var decoded report.Report
+	assert.NoError(json.Unmarshal(buf.Bytes(), &decoded))
+	assert.Equal(*r, decoded)
+
+	buf.Reset()
+	assert.NoError(r.Write(&buf, report.Text))
+	assert.Contains(buf.String(), "alice gets back 29.16 USD\n")
+	assert.Contains(buf.String(), "bob owes alice 18.33 USD\n")
+
+	buf.Reset()
+	assert.NoError(r.Write(&buf, report.Table))
+	assert.Contains(buf.String(), `Member  Balance (USD)
+------  -------------
+alice           29.16
+bob             -8.33
+`)
+}
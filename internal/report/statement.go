@@ -0,0 +1,104 @@
+package report
+
+import (
+	"encoding/csv"
+	"fmt"
+	"github.com/MeysamBavi/group-expense-manager/internal/storage"
+	"github.com/MeysamBavi/group-expense-manager/pkg/ledger"
+	"io"
+	"strings"
+)
+
+// Statement holds the records that change the balance of a member, with a running balance. The amounts are formatted
+// with the fraction digits of the group and are in its base currency.
+type Statement struct {
+	File     string           `json:"file"`
+	Currency string           `json:"currency"`
+	Member   string           `json:"member"`
+	Entries  []StatementEntry `json:"entries"`
+	// Balance is the net balance of the member. A positive amount means the member should get money back.
+	Balance string `json:"balance"`
+}
+
+// StatementEntry is a record of a statement. Credit is what the others owe the member because of the record, and
+// Debit is what the member owes the others.
+type StatementEntry struct {
+	Time        string `json:"time"`
+	Kind        string `json:"kind"`
+	Description string `json:"description"`
+	Credit      string `json:"credit"`
+	Debit       string `json:"debit"`
+	Balance     string `json:"balance"`
+}
+
+// StatementOf returns the statement of a member of a store kept in a file, from the entries computed by
+// ledger.ComputeStatement.
+func StatementOf(s storage.Store, fileName, memberName string, entries []ledger.StatementEntry) *Statement {
+	fractionDigits := s.Settings().FractionDigits
+	st := &Statement{
+		File:     fileName,
+		Currency: s.Settings().BaseCurrency,
+		Member:   memberName,
+		Entries:  []StatementEntry{},
+		Balance:  ledger.AmountZero().Format(fractionDigits),
+	}
+	for _, entry := range entries {
+		t := ""
+		if entry.Time != nil {
+			t = entry.Time.String()
+		}
+		st.Entries = append(st.Entries, StatementEntry{
+			Time:        t,
+			Kind:        string(entry.Kind),
+			Description: entry.Description,
+			Credit:      entry.Credit.Format(fractionDigits),
+			Debit:       entry.Debit.Format(fractionDigits),
+			Balance:     entry.Balance.Format(fractionDigits),
+		})
+		st.Balance = entry.Balance.Format(fractionDigits)
+	}
+	return st
+}
+
+// Write writes the statement in a format.
+func (st *Statement) Write(w io.Writer, format Format) error {
+	switch format {
+	case JSON:
+		return writeJSON(w, st)
+	case CSV:
+		return st.writeCSV(w)
+	default:
+		return st.writeText(w)
+	}
+}
+
+func (st *Statement) writeText(w io.Writer) error {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Statement of %s\n\n", st.Member)
+
+	var rows [][]string
+	for _, entry := range st.Entries {
+		credit, debit := entry.Credit, entry.Debit
+		if isZero(credit) {
+			credit = ""
+		}
+		if isZero(debit) {
+			debit = ""
+		}
+		rows = append(rows, []string{entry.Time, entry.Description, credit, debit, entry.Balance})
+	}
+	writeTable(&sb, []string{"Time", "Description", "Credit", "Debit", "Balance"}, rows, 2)
+
+	fmt.Fprintf(&sb, "\n%s\n", balanceSentence(st.Member, st.Balance, st.Currency))
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func (st *Statement) writeCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	records := [][]string{{"time", "kind", "description", "credit", "debit", "balance"}}
+	for _, entry := range st.Entries {
+		records = append(records, []string{entry.Time, entry.Kind, entry.Description, entry.Credit, entry.Debit, entry.Balance})
+	}
+	return writer.WriteAll(records)
+}
@@ -0,0 +1,220 @@
+package sheet
+
+import (
+	"errors"
+	"github.com/MeysamBavi/group-expense-manager/internal/log"
+	"github.com/MeysamBavi/group-expense-manager/internal/model"
+	"github.com/MeysamBavi/group-expense-manager/internal/sheet/store"
+	"github.com/MeysamBavi/group-expense-manager/internal/sheet/style"
+	"github.com/MeysamBavi/group-expense-manager/internal/sheet/table"
+	"github.com/MeysamBavi/group-expense-manager/pkg/ledger"
+	"strings"
+)
+
+func initializeSettlementConstraints(m *Manager) error {
+	m.constraints = &ledger.SettlementConstraints{MinimumAmount: model.AmountZero()}
+	return m.writeSettlementConstraints()
+}
+
+// SettlementConstraints returns the constraints of the settlement constraints sheet.
+func (m *Manager) SettlementConstraints() *ledger.SettlementConstraints {
+	return m.constraints
+}
+
+// SetSettlementConstraints replaces the constraints and rewrites the settlement constraints sheet. The constraints should
+// be valid for the members of the spreadsheet; nil means no constraints.
+func (m *Manager) SetSettlementConstraints(constraints *ledger.SettlementConstraints) error {
+	m.constraints = copySettlementConstraints(constraints)
+	return m.writeSettlementConstraints()
+}
+
+// copySettlementConstraints returns a copy of the constraints that does not share the blocked pairs, or empty
+// constraints if they are nil.
+func copySettlementConstraints(constraints *ledger.SettlementConstraints) *ledger.SettlementConstraints {
+	if constraints == nil {
+		return &ledger.SettlementConstraints{MinimumAmount: model.AmountZero()}
+	}
+	c := *constraints
+	c.Blocked = append([]ledger.BlockedPair(nil), constraints.Blocked...)
+	c.Preferred = append([]ledger.PreferredPair(nil), constraints.Preferred...)
+	return &c
+}
+
+// writeSettlementConstraints writes the treasurer and the minimum settlement in the first table of the settlement
+// constraints sheet, the blocked pairs in the second one and the preferred pairs in the third one.
+func (m *Manager) writeSettlementConstraints() error {
+	err := m.constraintsTable.WriteRows(table.WriteRowsParams{
+		RowCount: 2,
+		HeaderWriter: func(cells []*table.WCell, mergeCount *int) {
+			*mergeCount = m.constraintsTable.ColumnCount
+			cells[0].Value = "Settlements satisfy these constraints. Leave a value empty to not use it."
+			cells[0].Style = newInt(m.getStyle(helpStyle))
+		},
+		RowWriter: func(rowNumber int, cells []*table.WCell) {
+			cells[0].Style = newInt(m.getStyle(headerBoxStyle))
+			switch rowNumber {
+			case 0:
+				cells[0].Value = "Treasurer"
+				cells[1].Value = m.constraints.Treasurer
+			case 1:
+				cells[0].Value = "Minimum Settlement"
+				if m.constraints.MinimumAmount.IsPositive() {
+					cells[1].Value = m.amountValue(m.constraints.MinimumAmount)
+					cells[1].Style = newInt(m.getStyle(moneyStyle))
+				}
+			}
+		},
+		ColumnWidth:      24,
+		ClearBeforeWrite: true,
+	})
+	if err != nil {
+		return err
+	}
+
+	blocked := m.constraints.Blocked
+	err = m.writePairs(m.blockedPairsTable, "The payer of each row never pays the receiver of the row.", len(blocked),
+		func(i int) (string, string) {
+			return blocked[i].PayerName, blocked[i].ReceiverName
+		})
+	if err != nil {
+		return err
+	}
+
+	preferred := m.constraints.Preferred
+	return m.writePairs(m.preferencesTable, "The payer of each row pays the receiver of the row before anyone else.",
+		len(preferred), func(i int) (string, string) {
+			return preferred[i].PayerName, preferred[i].ReceiverName
+		})
+}
+
+// writePairs writes pairs of a payer and a receiver below a help text and a header row.
+func (m *Manager) writePairs(t *table.Table, help string, count int, pair func(i int) (string, string)) error {
+	params := table.WriteRowsParams{
+		RowCount: count + 1,
+		HeaderWriter: func(cells []*table.WCell, mergeCount *int) {
+			*mergeCount = t.ColumnCount
+			cells[0].Value = help
+			cells[0].Style = newInt(m.getStyle(helpStyle))
+		},
+		RowWriter: func(rowNumber int, cells []*table.WCell) {
+			if rowNumber == 0 {
+				cells[0].Value = "Payer"
+				cells[1].Value = "Receiver"
+				return
+			}
+			cells[0].Value, cells[1].Value = pair(rowNumber - 1)
+		},
+		ColumnWidth: 24,
+		RowStyler: func(row int) (int, bool) {
+			if row == 0 {
+				return m.getStyle(headerBoxStyle), true
+			}
+			return 0, false
+		},
+	}
+	if count > 0 {
+		params.ConditionalStyles = style.Alternate(m.getStyle(alternate0Style), m.getStyle(alternate1Style)).
+			WithStart(1, 0).
+			WithEnd(count, t.ColumnCount-1).
+			Build()
+	}
+	return t.WriteRows(params)
+}
+
+// loadSettlementConstraints reads the settlement constraints. It does not stop at the first invalid cell; all the
+// errors are returned together.
+func loadSettlementConstraints(constraintsTable, blockedPairsTable, preferencesTable *table.Table,
+	members *store.MemberStore) (*ledger.SettlementConstraints, error) {
+	var errs log.ErrorList
+	constraints := &ledger.SettlementConstraints{MinimumAmount: model.AmountZero()}
+	memberName := func(t *table.Table, value string, row, column int) string {
+		value = strings.TrimSpace(value)
+		err := checkMemberPresence(members, value, t.SheetName, t.GetCell(row, column))
+		errs.Add(err)
+		if err != nil {
+			return ""
+		}
+		member, _ := members.GetMemberByName(value)
+		return member.Name
+	}
+
+	err := constraintsTable.ReadRows(table.ReadRowsParams{
+		RowCount: 2,
+		RowReader: func(rowNumber int, cells []*table.RCell) {
+			value := strings.TrimSpace(cells[1].Value)
+			if value == "" {
+				return
+			}
+			switch rowNumber {
+			case 0:
+				constraints.Treasurer = memberName(constraintsTable, value, rowNumber, 1)
+			case 1:
+				amount, err := model.ParseAmount(value)
+				if err == nil && amount.IsNegative() {
+					err = errors.New("minimum settlement should not be negative")
+				}
+				errs.Add(log.CellErrorOf(err, constraintsTable.SheetName, constraintsTable.GetCell(rowNumber, 1)))
+				if err == nil {
+					constraints.MinimumAmount = amount
+				}
+			}
+		},
+	})
+	errs.Add(err)
+
+	readPairs := func(t *table.Table, add func(payer, receiver string)) error {
+		return t.ReadRows(table.ReadRowsParams{
+			RowReader: func(rowNumber int, cells []*table.RCell) {
+				if rowNumber == 0 {
+					return
+				}
+				payer := memberName(t, cells[0].Value, rowNumber, 0)
+				receiver := memberName(t, cells[1].Value, rowNumber, 1)
+				if payer == "" || receiver == "" {
+					return
+				}
+				if payer == receiver {
+					errs.Add(log.CellErrorOf(errors.New("payer and receiver should be different members"),
+						t.SheetName, t.GetCell(rowNumber, 1)))
+					return
+				}
+				add(payer, receiver)
+			},
+			UnknownRowCount: true,
+		})
+	}
+	errs.Add(readPairs(blockedPairsTable, func(payer, receiver string) {
+		constraints.Blocked = append(constraints.Blocked, ledger.BlockedPair{PayerName: payer, ReceiverName: receiver})
+	}))
+	// the spreadsheets created before the preferred pairs were kept have an empty third table
+	errs.Add(readPairs(preferencesTable, func(payer, receiver string) {
+		constraints.Preferred = append(constraints.Preferred, ledger.PreferredPair{PayerName: payer, ReceiverName: receiver})
+	}))
+
+	return constraints, errs.Err()
+}
+
+// removeFromSettlementConstraints removes the constraints of a member: they are no longer the treasurer and their
+// blocked and preferred pairs are removed.
+func (m *Manager) removeFromSettlementConstraints(memberIndex int) {
+	isMember := func(name string) bool {
+		return name != "" && m.members.GetIndexByName(name) == memberIndex
+	}
+	if isMember(m.constraints.Treasurer) {
+		m.constraints.Treasurer = ""
+	}
+	var blocked []ledger.BlockedPair
+	for _, pair := range m.constraints.Blocked {
+		if !isMember(pair.PayerName) && !isMember(pair.ReceiverName) {
+			blocked = append(blocked, pair)
+		}
+	}
+	m.constraints.Blocked = blocked
+	var preferred []ledger.PreferredPair
+	for _, pair := range m.constraints.Preferred {
+		if !isMember(pair.PayerName) && !isMember(pair.ReceiverName) {
+			preferred = append(preferred, pair)
+		}
+	}
+	m.constraints.Preferred = preferred
+}
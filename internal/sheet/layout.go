@@ -0,0 +1,114 @@
+package sheet
+
+import (
+	"errors"
+	"github.com/MeysamBavi/group-expense-manager/internal/log"
+	"github.com/xuri/excelize/v2"
+	"strings"
+)
+
+const (
+	timeHeader        = "Time"
+	titleHeader       = "Title"
+	payerHeader       = "Payer"
+	totalAmountHeader = "Total Amount"
+	currencyHeader    = "Currency"
+	splitHeader       = "Split"
+	shareWeightHeader = "Share Weight"
+	shareAmountHeader = "Share Amount"
+	paidHeader        = "Paid"
+)
+
+// expensesLayout describes the columns of the expenses sheet. Spreadsheets created by older versions have fewer columns,
+// so the layout of a loaded spreadsheet is detected from its headers.
+type expensesLayout struct {
+	// commonHeaders are the headers of the expensesLeftTable.
+	commonHeaders []string
+	// memberHeaders are the headers of the columns that are repeated for every member in the expensesRightTable.
+	memberHeaders []string
+}
+
+func latestExpensesLayout() *expensesLayout {
+	return &expensesLayout{
+		commonHeaders: []string{timeHeader, titleHeader, payerHeader, totalAmountHeader, currencyHeader, splitHeader},
+		memberHeaders: []string{shareWeightHeader, shareAmountHeader, paidHeader},
+	}
+}
+
+// commonColumn returns the column of the header in the expensesLeftTable, or -1 if the layout does not have it.
+func (l *expensesLayout) commonColumn(header string) int {
+	return indexOf(l.commonHeaders, header)
+}
+
+// memberColumn returns the column of the header of a member in the expensesRightTable, or -1 if the layout does not have it.
+func (l *expensesLayout) memberColumn(memberIndex int, header string) int {
+	i := indexOf(l.memberHeaders, header)
+	if i == -1 {
+		return -1
+	}
+	return memberIndex*len(l.memberHeaders) + i
+}
+
+// fullColumn returns the column of the header of a member in the expensesFullTable, or -1 if the layout does not have it.
+func (l *expensesLayout) fullColumn(memberIndex int, header string) int {
+	c := l.memberColumn(memberIndex, header)
+	if c == -1 {
+		return -1
+	}
+	return len(l.commonHeaders) + c
+}
+
+func loadExpensesLayout(file *excelize.File) (*expensesLayout, error) {
+	rows, err := file.GetRows(expensesSheet)
+	if err != nil {
+		return nil, log.SheetErrorOf(err, expensesSheet)
+	}
+
+	var headers []string
+	if subHeaderRow := expensesRightSideRowOffset - 1; subHeaderRow < len(rows) {
+		headers = rows[subHeaderRow]
+		if len(headers) >= expensesLeftSideColOffset {
+			headers = headers[expensesLeftSideColOffset-1:]
+		}
+	}
+
+	layout := &expensesLayout{}
+	i := 0
+	for ; i < len(headers) && strings.TrimSpace(headers[i]) != shareWeightHeader; i++ {
+		layout.commonHeaders = append(layout.commonHeaders, strings.TrimSpace(headers[i]))
+	}
+	if i < len(headers) {
+		layout.memberHeaders = append(layout.memberHeaders, shareWeightHeader)
+		for i++; i < len(headers) && strings.TrimSpace(headers[i]) != shareWeightHeader; i++ {
+			layout.memberHeaders = append(layout.memberHeaders, strings.TrimSpace(headers[i]))
+		}
+	}
+
+	var errs log.ErrorList
+	for _, header := range []string{timeHeader, titleHeader, payerHeader, totalAmountHeader} {
+		if layout.commonColumn(header) == -1 {
+			errs.Add(log.SheetErrorOf(errors.New("found no "+header+" column"), expensesSheet))
+		}
+	}
+	if indexOf(layout.memberHeaders, shareAmountHeader) == -1 {
+		errs.Add(log.SheetErrorOf(errors.New("found no "+shareAmountHeader+" column"), expensesSheet))
+	}
+
+	return layout, errs.Err()
+}
+
+func indexOf(values []string, value string) int {
+	for i, v := range values {
+		if v == value {
+			return i
+		}
+	}
+	return -1
+}
+
+// isLatest reports whether the layout has the same columns as the latest layout.
+func (l *expensesLayout) isLatest() bool {
+	latest := latestExpensesLayout()
+	return strings.Join(l.commonHeaders, "\n") == strings.Join(latest.commonHeaders, "\n") &&
+		strings.Join(l.memberHeaders, "\n") == strings.Join(latest.memberHeaders, "\n")
+}
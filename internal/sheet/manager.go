@@ -7,8 +7,8 @@ import (
 	"github.com/MeysamBavi/group-expense-manager/internal/sheet/store"
 	"github.com/MeysamBavi/group-expense-manager/internal/sheet/style"
 	"github.com/MeysamBavi/group-expense-manager/internal/sheet/table"
+	"github.com/MeysamBavi/group-expense-manager/pkg/ledger"
 	"github.com/xuri/excelize/v2"
-	"sort"
 	"strings"
 	"time"
 )
@@ -21,12 +21,16 @@ const (
 	debtMatrixSheet   = "debt matrix"
 	settlementsSheet  = "settlements"
 	baseStateSheet    = "base state"
+	ratesSheet        = "rates"
 	metadataSheet     = "metadata"
+
+	settlementConstraintsSheet = "settlement constraints"
 )
 
 type Manager struct {
 	file               *excelize.File
 	members            *store.MemberStore
+	rates              *store.RateStore
 	expenses           []*model.Expense
 	transactions       []*model.Transaction
 	debtMatrix         [][]model.Amount
@@ -42,22 +46,34 @@ type Manager struct {
 	settlementsTable   *table.Table
 	baseStateTable     *table.Table
 	metadataTable      *table.Table
+	ratesTable         *table.Table
+	constraintsTable   *table.Table
+	blockedPairsTable  *table.Table
+	preferencesTable   *table.Table
+	expensesLayout     *expensesLayout
 	theme              *style.Theme
+	settings           *Settings
+	constraints        *ledger.SettlementConstraints
 }
 
-func NewManager(memberStore *store.MemberStore, theme *style.Theme) *Manager {
+func NewManager(memberStore *store.MemberStore, theme *style.Theme, settings *Settings) (*Manager, error) {
 	m := newBaseManager()
 	m.file = excelize.NewFile()
 	m.members = memberStore
 	m.theme = theme
+	m.settings = settings
+	m.expensesLayout = latestExpensesLayout()
 
 	m.membersTable = newMembersTable(m.file)
 	setTablesExceptMembers(m)
 
 	createStyles(m)
-	createSheets(m)
+	err := createSheets(m)
+	if err != nil {
+		return nil, err
+	}
 
-	return m
+	return m, nil
 }
 
 func LoadManager(fileName string) (*Manager, error) {
@@ -70,16 +86,66 @@ func LoadManager(fileName string) (*Manager, error) {
 	m.file = file
 
 	m.membersTable = newMembersTable(m.file)
-	m.members = loadMembers(m.membersTable)
+	m.members, err = loadMembers(m.membersTable)
+	if err != nil {
+		return nil, err
+	}
+	m.expensesLayout, err = loadExpensesLayout(m.file)
+	if err != nil {
+		return nil, err
+	}
 	setTablesExceptMembers(m)
 
-	m.theme = loadMetadata(m.metadataTable)
-	m.expenses = loadExpenses(m.expensesFullTable, m.members)
-	m.transactions = loadTransactions(m.transactionsTable, m.members)
-	m.baseState = loadBaseState(m.baseStateTable, m.members)
+	m.theme, m.settings, err = loadMetadata(m.metadataTable)
+	if err != nil {
+		return nil, err
+	}
+	var errs log.ErrorList
+	hasRates := m.hasSheet(ratesSheet)
+	if hasRates {
+		m.rates, err = loadRates(m.ratesTable, m.settings.BaseCurrency)
+		errs.Add(err)
+	} else {
+		m.rates = store.NewRateStore(m.settings.BaseCurrency)
+	}
+	m.expenses, err = loadExpenses(m.expensesFullTable, m.expensesLayout, m.members, m.rates)
+	errs.Add(err)
+	m.transactions, err = loadTransactions(m.transactionsTable, m.members, m.rates)
+	errs.Add(err)
+	m.baseState, err = loadBaseState(m.baseStateTable, m.members)
+	errs.Add(err)
+	hasConstraints := m.hasSheet(settlementConstraintsSheet)
+	if hasConstraints {
+		m.constraints, err = loadSettlementConstraints(m.constraintsTable, m.blockedPairsTable, m.preferencesTable, m.members)
+		errs.Add(err)
+	}
+	if err := errs.Err(); err != nil {
+		return nil, err
+	}
 
 	createStyles(m)
 
+	if !hasRates {
+		_, err = m.file.NewSheet(ratesSheet)
+		if err != nil {
+			return nil, err
+		}
+		err = initializeRates(m)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if !hasConstraints {
+		_, err = m.file.NewSheet(settlementConstraintsSheet)
+		if err != nil {
+			return nil, err
+		}
+		err = initializeSettlementConstraints(m)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return m, nil
 }
 
@@ -89,57 +155,103 @@ func newBaseManager() *Manager {
 	}
 }
 
-func (m *Manager) PrintData(summarize bool) {
-	fmt.Println("Members:")
-	m.members.Range(func(index int, member *model.Member) {
-		fmt.Println(*member)
-	})
-
-	fmt.Println("Expenses:")
-	if summarize {
-		fmt.Println("Count:", len(m.expenses))
-	} else {
-		for _, expense := range m.expenses {
-			fmt.Println(*expense)
-		}
+func (m *Manager) SaveAs(name string) error {
+	err := m.file.SetSheetVisible(metadataSheet, false)
+	if err != nil {
+		return err
 	}
+	return m.file.SaveAs(name)
+}
 
-	fmt.Println("Transactions:")
-	if summarize {
-		fmt.Println("Count:", len(m.transactions))
-	} else {
-		for _, transaction := range m.transactions {
-			fmt.Println(*transaction)
-		}
+func (m *Manager) hasSheet(name string) bool {
+	index, err := m.file.GetSheetIndex(name)
+	return err == nil && index != -1
+}
+
+func (m *Manager) MembersCount() int {
+	return m.members.Count()
+}
+
+func (m *Manager) UpdateDebtors() error {
+	err := m.calculateDebtMatrix()
+	if err != nil {
+		return err
+	}
+	err = m.writeDebtMatrix()
+	if err != nil {
+		return err
 	}
+	err = m.calculateSettlements()
+	if err != nil {
+		return err
+	}
+	return m.writeSettlements()
+}
+
+func (m *Manager) Settings() *Settings {
+	return m.settings
+}
+
+// SetSettings replaces the settings and rewrites the metadata sheet. The records and the settlements are not changed.
+func (m *Manager) SetSettings(settings *Settings) error {
+	m.settings = settings
+	return m.writeMetadata()
+}
 
-	fmt.Println("Debt Matrix:")
-	fmt.Println(m.debtMatrix)
+func (m *Manager) Theme() *style.Theme {
+	return m.theme
+}
 
-	fmt.Println("Settlements:")
-	for _, settlement := range m.settlements {
-		fmt.Println(*settlement)
+func (m *Manager) Rates() *store.RateStore {
+	return m.rates
+}
+
+// ReplaceRecords replaces the rates, expenses, transactions and base state and rewrites their sheets.
+// The records should be valid for the members of the spreadsheet. A dummy row is added before the expenses.
+func (m *Manager) ReplaceRecords(rates *store.RateStore, expenses []*model.Expense, transactions []*model.Transaction, baseState [][]model.Amount) error {
+	m.rates = rates
+	m.expenses = append([]*model.Expense{newTemplateExpense(m)}, expenses...)
+	m.transactions = transactions
+	m.baseState = baseState
+	for _, write := range []func() error{m.writeRates, m.writeExpenses, m.writeTransactions, m.writeBaseState} {
+		if err := write(); err != nil {
+			return err
+		}
 	}
+	return nil
+}
 
-	fmt.Println("Base State:")
-	fmt.Println(m.baseState)
+func (m *Manager) DebtMatrix() [][]model.Amount {
+	return m.debtMatrix
 }
 
-func (m *Manager) SaveAs(name string) error {
-	err := m.file.SetSheetVisible(metadataSheet, false)
-	fatalIfNotNil(err)
-	return m.file.SaveAs(name)
+func (m *Manager) Settlements() []*model.Transaction {
+	return m.settlements
 }
 
-func (m *Manager) MembersCount() int {
-	return m.members.Count()
+// SetDebtMatrix replaces the debt matrix and writes it to the debt matrix sheet.
+func (m *Manager) SetDebtMatrix(debtMatrix [][]model.Amount) error {
+	m.debtMatrix = debtMatrix
+	return m.writeDebtMatrix()
 }
 
-func (m *Manager) UpdateDebtors() {
-	m.calculateDebtMatrix()
-	m.writeDebtMatrix()
-	m.calculateSettlements()
-	m.writeSettlements()
+// SetSettlements replaces the settlements and writes them to the settlements sheet. If the spreadsheet has a
+// settlement steps sheet, it is rewritten too.
+func (m *Manager) SetSettlements(settlements []*model.Transaction) error {
+	m.settlements = settlements
+	err := m.writeSettlements()
+	if err != nil {
+		return err
+	}
+	if m.hasSheet(settlementStepsSheet) {
+		return m.writeSettlementSteps()
+	}
+	return nil
+}
+
+// amountValue returns the value of an amount to be written in a cell, based on the fraction digits of the spreadsheet.
+func (m *Manager) amountValue(amount model.Amount) any {
+	return amount.ToFloat(m.settings.FractionDigits)
 }
 
 func (m *Manager) setStyle(key int, value *excelize.Style) {
@@ -156,43 +268,39 @@ func (m *Manager) getStyle(key int) int {
 	return m.styleIndices[key]
 }
 
-func (m *Manager) calculateDebtMatrix() {
-	debtMatrix := copyMatrix(m.baseState)
-
-	for _, expense := range m.expenses {
-		payerIndex := m.members.GetIndexByName(expense.PayerName)
-		for _, share := range expense.Shares {
-			memberIndex := m.members.GetIndexByName(share.MemberName)
-			debtMatrix[memberIndex][payerIndex] =
-				debtMatrix[memberIndex][payerIndex].Add(
-					expense.Amount.Divide(expense.SumOfWeights()).Multiply(share.ShareWeight))
-		}
+// Ledger returns the members, expenses, transactions, base state and settings of the spreadsheet.
+// The dummy row of the expenses sheet is not included.
+func (m *Manager) Ledger() *ledger.Ledger {
+	members := make([]*model.Member, 0, m.MembersCount())
+	m.members.Range(func(_ int, member *model.Member) {
+		members = append(members, member)
+	})
+	expenses := m.expenses
+	if _, ok := m.templateExpense(); ok {
+		expenses = expenses[1:]
 	}
-
-	for _, transaction := range m.transactions {
-		receiverIndex := m.members.GetIndexByName(transaction.ReceiverName)
-		payerIndex := m.members.GetIndexByName(transaction.PayerName)
-		debtMatrix[payerIndex][receiverIndex] =
-			debtMatrix[payerIndex][receiverIndex].Sub(transaction.Amount)
+	return &ledger.Ledger{
+		Members:        members,
+		Expenses:       expenses,
+		Transactions:   m.transactions,
+		BaseState:      m.baseState,
+		FractionDigits: m.settings.FractionDigits,
+		Rounding:       m.settings.Rounding,
+		Rates:          m.rates,
 	}
+}
 
-	for r := 0; r < m.MembersCount(); r++ {
-		for c := r; c < m.MembersCount(); c++ {
-			if debtMatrix[r][c].LessThan(debtMatrix[c][r]) {
-				debtMatrix[c][r] = debtMatrix[c][r].Sub(debtMatrix[r][c])
-				debtMatrix[r][c] = model.AmountZero()
-			} else {
-				debtMatrix[r][c] = debtMatrix[r][c].Sub(debtMatrix[c][r])
-				debtMatrix[c][r] = model.AmountZero()
-			}
-		}
+func (m *Manager) calculateDebtMatrix() error {
+	debtMatrix, err := ledger.ComputeDebtMatrix(m.Ledger())
+	if err != nil {
+		return err
 	}
-
 	m.debtMatrix = debtMatrix
+	return nil
 }
 
-func (m *Manager) writeDebtMatrix() {
-	m.debtMatrixTable.WriteRows(table.WriteRowsParams{
+func (m *Manager) writeDebtMatrix() error {
+	return m.debtMatrixTable.WriteRows(table.WriteRowsParams{
 		HeaderWriter: func(cells []*table.WCell, mergeCount *int) {
 			*mergeCount = m.debtMatrixTable.ColumnCount
 			cells[0].Value = "Run 'update' command to update the debt matrix. Person in the row should pay the person in the column."
@@ -215,7 +323,7 @@ func (m *Manager) writeDebtMatrix() {
 			cells[0].Style = newInt(m.getStyle(headerBoxStyle))
 			for i := 0; i < m.MembersCount(); i++ {
 				amount := m.debtMatrix[memberIndex][i]
-				cells[i+1].Value = amount.ToNumeral()
+				cells[i+1].Value = m.amountValue(amount)
 				if amount.IsZero() {
 					cells[i+1].Value = ""
 				}
@@ -234,11 +342,12 @@ func (m *Manager) writeDebtMatrix() {
 			WithModOffset(1).
 			WithEnd(m.MembersCount(), m.MembersCount()).
 			Build(),
+		ClearBeforeWrite: true,
 	})
 }
 
-func (m *Manager) writeBaseState() {
-	m.baseStateTable.WriteRows(table.WriteRowsParams{
+func (m *Manager) writeBaseState() error {
+	return m.baseStateTable.WriteRows(table.WriteRowsParams{
 		RowCount: m.MembersCount(),
 		HeaderWriter: func(cells []*table.WCell, mergeCount *int) {
 			m.members.Range(func(i int, member *model.Member) {
@@ -251,7 +360,7 @@ func (m *Manager) writeBaseState() {
 			cells[0].Style = newInt(m.getStyle(headerBoxStyle))
 			for i := 0; i < m.MembersCount(); i++ {
 				amount := m.baseState[rowNumber][i]
-				cells[i+1].Value = amount.ToNumeral()
+				cells[i+1].Value = m.amountValue(amount)
 				if amount.IsZero() {
 					cells[i+1].Value = ""
 				}
@@ -268,78 +377,19 @@ func (m *Manager) writeBaseState() {
 			WithStart(0, 1).
 			WithEnd(m.MembersCount()-1, m.MembersCount()).
 			Build(),
+		ClearBeforeWrite: true,
 	})
 }
 
-func (m *Manager) calculateSettlements() {
-	type balance struct {
-		name   string
-		amount model.Amount // positive means debtor
-	}
-
-	balances := make([]balance, 0, m.MembersCount())
-	m.members.Range(func(memberIndex int, member *model.Member) {
-		gives, receives := model.AmountZero(), model.AmountZero()
-		for i := 0; i < m.MembersCount(); i++ {
-			receives = receives.Add(m.debtMatrix[i][memberIndex])
-		}
-		for i := 0; i < m.MembersCount(); i++ {
-			gives = gives.Add(m.debtMatrix[memberIndex][i])
-		}
-		balances = append(balances, balance{
-			name:   member.Name,
-			amount: gives.Sub(receives),
-		})
-	})
-
-	sort.SliceStable(balances, func(i, j int) bool {
-		return balances[i].amount.LessThan(balances[j].amount)
-	})
-
-	settlements := make([]*model.Transaction, 0)
-	addSettlement := func(receiver, payer int, amount model.Amount) {
-		if amount.IsZero() {
-			return
-		}
-		if amount.LessThan(model.AmountZero()) {
-			receiver, payer = payer, receiver
-			amount = amount.Negative()
-		}
-		settlements = append(settlements, &model.Transaction{
-			ReceiverName: balances[receiver].name,
-			PayerName:    balances[payer].name,
-			Amount:       amount,
-		})
-		balances[payer].amount = balances[payer].amount.Sub(amount)
-		balances[receiver].amount = balances[receiver].amount.Add(amount)
-	}
-	lowest, highest := 0, len(balances)-1
-	for highest > lowest {
-		if balances[lowest].amount.IsZero() {
-			lowest++
-			continue
-		}
-		if balances[highest].amount.IsZero() {
-			highest--
-			continue
-		}
-
-		deficit := balances[highest].amount.Add(balances[lowest].amount)
-		if deficit.IsPositive() {
-			addSettlement(lowest, highest, balances[lowest].amount.Negative())
-		} else {
-			addSettlement(lowest, highest, balances[highest].amount)
-		}
-	}
-
-	sort.SliceStable(settlements, func(i, j int) bool {
-		return settlements[i].Amount.LessThan(settlements[j].Amount)
-	})
-	m.settlements = settlements
+func (m *Manager) calculateSettlements() error {
+	var err error
+	m.settlements, err = ledger.ComputeConstrainedSettlements(m.Ledger().Members, m.debtMatrix, m.settings.Settlement,
+		m.constraints)
+	return err
 }
 
-func (m *Manager) writeSettlements() {
-	m.settlementsTable.WriteRows(table.WriteRowsParams{
+func (m *Manager) writeSettlements() error {
+	return m.settlementsTable.WriteRows(table.WriteRowsParams{
 		RowCount: len(m.settlements) + 1,
 		HeaderWriter: func(cells []*table.WCell, mergeCount *int) {
 			*mergeCount = m.settlementsTable.ColumnCount
@@ -356,7 +406,7 @@ func (m *Manager) writeSettlements() {
 			rowNumber--
 			cells[0].Value = m.settlements[rowNumber].ReceiverName
 			cells[1].Value = m.settlements[rowNumber].PayerName
-			cells[2].Value = m.settlements[rowNumber].Amount.ToNumeral()
+			cells[2].Value = m.amountValue(m.settlements[rowNumber].Amount)
 			cells[2].Style = newInt(m.getStyle(moneyStyle))
 		},
 		ColumnWidth: 18,
@@ -375,55 +425,58 @@ func (m *Manager) writeSettlements() {
 }
 
 func setTablesExceptMembers(m *Manager) {
-	m.expensesLeftTable = newExpensesLeftTable(m.file)
-	m.expensesRightTable = newExpensesRightTable(m.file, m.MembersCount())
-	m.expensesFullTable = newExpensesFullTable(m.file, m.MembersCount())
+	m.expensesLeftTable = newExpensesLeftTable(m.file, m.expensesLayout)
+	m.expensesRightTable = newExpensesRightTable(m.file, m.expensesLayout, m.MembersCount())
+	m.expensesFullTable = newExpensesFullTable(m.file, m.expensesLayout, m.MembersCount())
 	m.transactionsTable = newTransactionsTable(m.file)
 	m.debtMatrixTable = newDebtMatrixTable(m.file, m.MembersCount())
 	m.settlementsTable = newSettlementsTable(m.file)
 	m.baseStateTable = newBaseStateTable(m.file, m.MembersCount())
 	m.metadataTable = newMetadataTable(m.file)
+	m.ratesTable = newRatesTable(m.file)
+	m.constraintsTable = newConstraintsTable(m.file)
+	m.blockedPairsTable = newBlockedPairsTable(m.file)
+	m.preferencesTable = newPreferencesTable(m.file)
 }
 
-func createSheets(m *Manager) {
-	fatalIfNotNil(m.file.SetSheetName(initialSheetName, membersSheet))
-	defer initializeMembers(m)
-
-	_, err := m.file.NewSheet(expensesSheet)
-	fatalIfNotNil(err)
-	defer initializeExpenses(m)
-
-	_, err = m.file.NewSheet(transactionsSheet)
-	fatalIfNotNil(err)
-	defer initializeTransactions(m)
-
-	_, err = m.file.NewSheet(debtMatrixSheet)
-	fatalIfNotNil(err)
-	defer initializeDebtMatrix(m)
+func createSheets(m *Manager) error {
+	err := m.file.SetSheetName(initialSheetName, membersSheet)
+	if err != nil {
+		return err
+	}
 
-	_, err = m.file.NewSheet(settlementsSheet)
-	fatalIfNotNil(err)
-	defer initializeSettlements(m)
+	for _, name := range []string{expensesSheet, transactionsSheet, debtMatrixSheet, settlementsSheet,
+		settlementConstraintsSheet, baseStateSheet, ratesSheet, metadataSheet} {
+		_, err = m.file.NewSheet(name)
+		if err != nil {
+			return err
+		}
+	}
 
-	_, err = m.file.NewSheet(baseStateSheet)
-	fatalIfNotNil(err)
-	defer initializeBaseState(m)
+	// sheets are initialized in the reverse order of creation
+	for _, initialize := range []func(*Manager) error{initializeMetadata, initializeRates, initializeBaseState,
+		initializeSettlementConstraints, initializeSettlements, initializeDebtMatrix, initializeTransactions, initializeExpenses, initializeMembers} {
+		if err = initialize(m); err != nil {
+			return err
+		}
+	}
 
-	_, err = m.file.NewSheet(metadataSheet)
-	fatalIfNotNil(err)
-	defer initializeMetadata(m)
+	return nil
 }
 
-func initializeMembers(m *Manager) {
-	m.membersTable.WriteRows(table.WriteRowsParams{
+func initializeMembers(m *Manager) error {
+	return m.membersTable.WriteRows(table.WriteRowsParams{
 		RowCount: m.MembersCount(),
 		HeaderWriter: func(cells []*table.WCell, _ *int) {
 			cells[0].Value = "Name"
 			cells[1].Value = "Card Number"
+			cells[2].Value = "Status"
 		},
 		RowWriter: func(rowNumber int, cells []*table.WCell) {
-			cells[0].Value = m.members.RequireMemberByIndex(rowNumber).Name
-			cells[1].Value = m.members.RequireMemberByIndex(rowNumber).CardNumber
+			member := m.members.RequireMemberByIndex(rowNumber)
+			cells[0].Value = member.Name
+			cells[1].Value = member.CardNumber
+			cells[2].Value = member.Status()
 		},
 		ColumnWidth: 32,
 		RowStyler: func(row int) (int, bool) {
@@ -434,33 +487,26 @@ func initializeMembers(m *Manager) {
 		},
 		ConditionalStyles: style.Alternate(m.getStyle(alternate0Style), m.getStyle(alternate1Style)).
 			WithStart(0, 0).
-			WithEnd(m.MembersCount()-1, 1).
+			WithEnd(m.MembersCount()-1, m.membersTable.ColumnCount-1).
 			Build(),
+		ClearBeforeWrite: true,
 	})
 }
 
-func initializeBaseState(m *Manager) {
-	m.baseState = emptyMatrix(m.MembersCount())
-	m.writeBaseState()
+func initializeBaseState(m *Manager) error {
+	m.baseState = ledger.EmptyMatrix(m.MembersCount())
+	return m.writeBaseState()
 }
 
-func initializeDebtMatrix(m *Manager) {
-	m.debtMatrix = emptyMatrix(m.MembersCount())
-	m.writeDebtMatrix()
+func initializeDebtMatrix(m *Manager) error {
+	m.debtMatrix = ledger.EmptyMatrix(m.MembersCount())
+	return m.writeDebtMatrix()
 }
 
-func initializeTransactions(m *Manager) {
-
-	m.transactionsTable.WriteRows(table.WriteRowsParams{
-		RowCount: 1,
-		HeaderWriter: func(cells []*table.WCell, _ *int) {
-			cells[0].Value = "Time"
-			cells[1].Value = "Receiver"
-			cells[2].Value = "Payer"
-			cells[3].Value = "Amount"
-		},
-		RowWriter: func(rowNumber int, cells []*table.WCell) {
-			cells[0].Value = model.TimeOfGregorian(time.Date(
+func initializeTransactions(m *Manager) error {
+	m.transactions = []*model.Transaction{
+		{
+			Time: model.TimeOfGregorian(time.Date(
 				2012,
 				time.June,
 				26,
@@ -468,12 +514,26 @@ func initializeTransactions(m *Manager) {
 				6,
 				0,
 				0,
-				time.Local)).String()
-			cells[1].Value = m.members.RequireMemberByIndex(0).Name
-			cells[2].Value = m.members.RequireMemberByIndex(1).Name
-			cells[3].Value = 0
-			cells[3].Style = newInt(m.getStyle(moneyStyle))
+				time.Local)),
+			ReceiverName: m.members.RequireMemberByIndex(0).Name,
+			PayerName:    m.members.RequireMemberByIndex(1).Name,
+			Amount:       model.AmountZero(),
 		},
+	}
+	return m.writeTransactions()
+}
+
+func (m *Manager) writeTransactions() error {
+	return m.transactionsTable.WriteRows(table.WriteRowsParams{
+		RowCount: len(m.transactions),
+		HeaderWriter: func(cells []*table.WCell, _ *int) {
+			cells[0].Value = "Time"
+			cells[1].Value = "Receiver"
+			cells[2].Value = "Payer"
+			cells[3].Value = "Amount"
+			cells[4].Value = "Currency"
+		},
+		RowWriter:   m.writeTransactionCells,
 		ColumnWidth: 18,
 		RowStyler: func(row int) (int, bool) {
 			if row == -1 {
@@ -483,39 +543,70 @@ func initializeTransactions(m *Manager) {
 		},
 		ConditionalStyles: style.Alternate(m.getStyle(alternate0Style), m.getStyle(alternate1Style), m.getStyle(alternate2Style)).
 			WithStart(0, 0).
-			WithEnd(0, m.transactionsTable.ColumnCount-1).
+			WithEnd(len(m.transactions)-1, m.transactionsTable.ColumnCount-1).
 			WithModOffset(2).
 			Build(),
+		ClearBeforeWrite: true,
+	})
+}
+
+// writeTransactionCells fills the cells of the given row of transactionsTable.
+func (m *Manager) writeTransactionCells(rowNumber int, cells []*table.WCell) {
+	transaction := m.transactions[rowNumber]
+	cells[0].Value = transaction.Time.String()
+	cells[1].Value = transaction.ReceiverName
+	cells[2].Value = transaction.PayerName
+	cells[3].Value = m.amountValue(transaction.Amount)
+	cells[3].Style = newInt(m.getStyle(moneyStyle))
+	cells[4].Value = transaction.Currency
+}
+
+func initializeExpenses(m *Manager) error {
+	m.expenses = []*model.Expense{newTemplateExpense(m)}
+	return m.writeExpenses()
+}
+
+// newTemplateExpense returns an expense with zero amount to be used as the dummy row of the expenses sheet.
+func newTemplateExpense(m *Manager) *model.Expense {
+	dummy := &model.Expense{
+		Title: "example",
+		Time: model.TimeOfGregorian(time.Date(
+			2007,
+			time.May,
+			13,
+			23,
+			57,
+			0,
+			0,
+			time.Local)),
+		PayerName: m.members.RequireMemberByIndex(0).Name,
+		Amount:    model.AmountZero(),
+		SplitMode: model.SplitByWeight,
+	}
+	m.members.Range(func(i int, member *model.Member) {
+		dummy.Shares = append(dummy.Shares, model.Share{
+			MemberName:  member.Name,
+			ShareWeight: i >> 1,
+		})
 	})
+	return dummy
 }
 
-func initializeExpenses(m *Manager) {
+// writeExpenses rewrites the whole expenses sheet based on m.expenses, using the latest layout.
+// The first expense is used as the dummy row.
+func (m *Manager) writeExpenses() error {
+	m.expensesLayout = latestExpensesLayout()
+	setTablesExceptMembers(m)
+	layout := m.expensesLayout
 
-	var totalAmountCell string
-	m.expensesLeftTable.WriteRows(table.WriteRowsParams{
-		RowCount: 1,
+	err := m.expensesLeftTable.WriteRows(table.WriteRowsParams{
+		RowCount: len(m.expenses),
 		HeaderWriter: func(cells []*table.WCell, mergeCount *int) {
-			cells[0].Value = "Time"
-			cells[1].Value = "Title"
-			cells[2].Value = "Payer"
-			cells[3].Value = "Total Amount"
-		},
-		RowWriter: func(rowNumber int, cells []*table.WCell) {
-			cells[0].Value = model.TimeOfGregorian(time.Date(
-				2007,
-				time.May,
-				13,
-				23,
-				57,
-				0,
-				0,
-				time.Local)).String()
-			cells[1].Value = "example"
-			cells[2].Value = m.members.RequireMemberByIndex(0).Name
-			cells[3].Value = 0
-			cells[3].Style = newInt(m.getStyle(moneyStyle))
-			totalAmountCell = m.expensesLeftTable.GetCell(rowNumber, 3)
+			for i, header := range layout.commonHeaders {
+				cells[i].Value = header
+			}
 		},
+		RowWriter:   m.writeExpenseCommonCells,
 		ColumnWidth: 16,
 		RowStyler: func(row int) (int, bool) {
 			if row == -1 {
@@ -525,32 +616,39 @@ func initializeExpenses(m *Manager) {
 		},
 		ConditionalStyles: style.Alternate(m.getStyle(alternate0Style), m.getStyle(alternate1Style), m.getStyle(alternate2Style)).
 			WithStart(0, 0).
-			WithEnd(0, m.expensesLeftTable.ColumnCount+m.expensesRightTable.ColumnCount-1).
+			WithEnd(len(m.expenses)-1, m.expensesLeftTable.ColumnCount+m.expensesRightTable.ColumnCount-1).
 			Build(),
+		ClearBeforeWrite: true,
+	})
+	if err != nil {
+		return err
+	}
+
+	// the share amounts are not converted, so they only add up to the debts in the base currency
+	helpTable := newExpensesHelpTable(m.file, layout)
+	err = helpTable.WriteRows(table.WriteRowsParams{
+		HeaderWriter: func(cells []*table.WCell, mergeCount *int) {
+			*mergeCount = helpTable.ColumnCount
+			cells[0].Value = "Amounts, including share amounts, are in the row's currency. Debts are in the base currency."
+			cells[0].Style = newInt(m.getStyle(helpStyle))
+		},
+		RowWriter: func(int, []*table.WCell) {},
 	})
+	if err != nil {
+		return err
+	}
 
-	var weightCells []string
-	m.expensesRightTable.WriteRows(table.WriteRowsParams{
-		RowCount: 2,
+	err = m.expensesRightTable.WriteRows(table.WriteRowsParams{
+		RowCount: len(m.expenses) + 1,
 		HeaderWriter: func(cells []*table.WCell, mergeCount *int) {
-			*mergeCount = 2
+			*mergeCount = len(layout.memberHeaders)
 			m.members.Range(func(i int, member *model.Member) {
-				cells[i*2].Value = member.Name
+				cells[i*len(layout.memberHeaders)].Value = member.Name
 			})
 		},
 		RowWriter: func(rowNumber int, cells []*table.WCell) {
-			for i := 0; i < m.MembersCount()*2; i += 2 {
-				if rowNumber == 0 {
-					cells[i].Value = "Share Weight"
-					wc := fmt.Sprintf("IF(%s=TRUE, 1, %s)", m.expensesRightTable.GetCell(1, i), m.expensesRightTable.GetCell(1, i))
-					weightCells = append(weightCells, wc)
-					cells[i+1].Value = "Share Amount"
-				} else if rowNumber == 1 {
-					cells[i].Value = i >> 2
-					totalWeightsFormula := fmt.Sprintf("SUM(%s)", strings.Join(weightCells, ", "))
-					cells[i+1].Formula = fmt.Sprintf("(%s/%s)*%s", m.expensesRightTable.GetCell(rowNumber, i), totalWeightsFormula, totalAmountCell)
-					cells[i+1].Style = newInt(m.getStyle(moneyStyle))
-				}
+			for i := 0; i < m.MembersCount(); i++ {
+				m.writeExpenseMemberCells(rowNumber, i, cells[i*len(layout.memberHeaders):])
 			}
 		},
 		RowStyler: func(row int) (int, bool) {
@@ -565,46 +663,140 @@ func initializeExpenses(m *Manager) {
 		},
 		ColumnWidth: 11,
 	})
+	if err != nil {
+		return err
+	}
+
+	m.members.Range(func(i int, member *model.Member) {
+		if member.Deactivated && err == nil {
+			err = m.expensesRightTable.SetColumnsVisible(i*len(layout.memberHeaders), (i+1)*len(layout.memberHeaders)-1, false)
+		}
+	})
+	return err
+}
+
+// writeExpenseCommonCells fills the cells of the given row of expensesLeftTable.
+func (m *Manager) writeExpenseCommonCells(rowNumber int, cells []*table.WCell) {
+	expense := m.expenses[rowNumber]
+	for i, header := range m.expensesLayout.commonHeaders {
+		switch header {
+		case timeHeader:
+			cells[i].Value = expense.Time.String()
+		case titleHeader:
+			cells[i].Value = expense.Title
+		case payerHeader:
+			cells[i].Value = expense.PayerName
+		case totalAmountHeader:
+			cells[i].Value = m.amountValue(expense.Amount)
+			cells[i].Style = newInt(m.getStyle(moneyStyle))
+		case currencyHeader:
+			cells[i].Value = expense.Currency
+		case splitHeader:
+			cells[i].Value = string(expense.SplitMode)
+		}
+	}
 }
 
-func initializeSettlements(m *Manager) {
-	m.settlements = make([]*model.Transaction, 0)
-	m.writeSettlements()
+// writeExpenseMemberCells fills the cells of a member in the given row of expensesRightTable.
+// Row 0 holds the headers and row n holds the (n-1)th expense.
+func (m *Manager) writeExpenseMemberCells(rowNumber, memberIndex int, cells []*table.WCell) {
+	for i, header := range m.expensesLayout.memberHeaders {
+		if rowNumber == 0 {
+			cells[i].Value = header
+			continue
+		}
+
+		switch header {
+		case shareWeightHeader:
+			expense := m.expenses[rowNumber-1]
+			if expense.UsesShareValues() {
+				cells[i].Value = expense.Shares[memberIndex].ShareValue.ToFloat(MaxFractionDigits)
+			} else {
+				cells[i].Value = expense.Shares[memberIndex].ShareWeight
+			}
+		case shareAmountHeader:
+			cells[i].Formula = m.shareAmountFormula(rowNumber, memberIndex)
+			cells[i].Style = newInt(m.getStyle(moneyStyle))
+		case paidHeader:
+			if paid := m.expenses[rowNumber-1].Shares[memberIndex].Paid; !paid.IsZero() {
+				cells[i].Value = m.amountValue(paid)
+			}
+			cells[i].Style = newInt(m.getStyle(moneyStyle))
+		}
+	}
 }
 
-func initializeMetadata(m *Manager) {
-	m.metadataTable.WriteRows(table.WriteRowsParams{
-		RowCount: 1,
-		RowWriter: func(rowNumber int, cells []*table.WCell) {
-			cells[0].Value = m.theme.Code()
-		},
-	})
+// shareAmountFormula returns the formula of the Share Amount cell of a member in the given row of expensesRightTable.
+// If the layout has a Split column, the formula adapts to the split mode of the expense.
+func (m *Manager) shareAmountFormula(rowNumber, memberIndex int) string {
+	layout := m.expensesLayout
+	weightCells := make([]string, 0, m.MembersCount())
+	for i := 0; i < m.MembersCount(); i++ {
+		wc := m.expensesRightTable.GetCell(rowNumber, layout.memberColumn(i, shareWeightHeader))
+		weightCells = append(weightCells, fmt.Sprintf("IF(%s=TRUE, 1, %s)", wc, wc))
+	}
+	totalWeightsFormula := fmt.Sprintf("SUM(%s)", strings.Join(weightCells, ", "))
+	leftRowNumber := rowNumber + expensesRightSideRowOffset - expensesLeftSideRowOffset
+	totalAmountCell := m.expensesLeftTable.GetCell(leftRowNumber, layout.commonColumn(totalAmountHeader))
+	weightCell := m.expensesRightTable.GetCell(rowNumber, layout.memberColumn(memberIndex, shareWeightHeader))
+
+	formula := fmt.Sprintf("(%s/%s)*%s", weightCell, totalWeightsFormula, totalAmountCell)
+	if splitColumn := layout.commonColumn(splitHeader); splitColumn != -1 {
+		splitCell := fmt.Sprintf("TRIM(%s)", m.expensesLeftTable.GetCell(leftRowNumber, splitColumn))
+		formula = fmt.Sprintf(`IF(%s="%s", %s, IF(%s="%s", %s*%s/100, %s))`,
+			splitCell, model.SplitByExact, weightCell,
+			splitCell, model.SplitByPercentage, weightCell, totalAmountCell,
+			formula)
+	}
+	return formula
 }
 
-func loadMembers(t *table.Table) *store.MemberStore {
+func initializeSettlements(m *Manager) error {
+	m.settlements = make([]*model.Transaction, 0)
+	return m.writeSettlements()
+}
+
+// loadMembers reads the members. It does not stop at the first invalid cell; all the errors are returned together.
+func loadMembers(t *table.Table) (*store.MemberStore, error) {
+	var errs log.ErrorList
 	members := store.NewMemberStore()
-	t.ReadRows(table.ReadRowsParams{
+	err := t.ReadRows(table.ReadRowsParams{
 		RowReader: func(rowNumber int, cells []*table.RCell) {
-			err := members.AddMember(&model.Member{
-				Name:       strings.TrimSpace(cells[0].Value),
-				CardNumber: strings.TrimSpace(cells[1].Value),
+			deactivated, err := model.ParseMemberStatus(cells[2].Value)
+			errs.Add(log.CellErrorOf(err, t.SheetName, t.GetCell(rowNumber, 2)))
+
+			err = members.AddMember(&model.Member{
+				Name:        strings.TrimSpace(cells[0].Value),
+				CardNumber:  strings.TrimSpace(cells[1].Value),
+				Deactivated: deactivated,
 			})
-			fatalIfNotNil(log.CellErrorOf(err, t.SheetName, t.GetCell(rowNumber, 0)))
+			errs.Add(log.CellErrorOf(err, t.SheetName, t.GetCell(rowNumber, 0)))
 		},
 		IncludeHeader:   false,
 		UnknownRowCount: true,
 	})
+	errs.Add(err)
 
-	return members
+	return members, errs.Err()
 }
 
-func loadExpenses(t *table.Table, members *store.MemberStore) []*model.Expense {
+// loadExpenses reads the expenses. It does not stop at the first invalid cell; all the errors are returned together.
+func loadExpenses(t *table.Table, layout *expensesLayout, members *store.MemberStore, rates *store.RateStore) ([]*model.Expense, error) {
+	timeColumn := layout.commonColumn(timeHeader)
+	titleColumn := layout.commonColumn(titleHeader)
+	payerColumn := layout.commonColumn(payerHeader)
+	amountColumn := layout.commonColumn(totalAmountHeader)
+	currencyColumn := layout.commonColumn(currencyHeader)
+	splitColumn := layout.commonColumn(splitHeader)
+
+	var errs log.ErrorList
 	var expenses []*model.Expense
-	t.ReadRows(table.ReadRowsParams{
+	err := t.ReadRows(table.ReadRowsParams{
 		RowReader: func(rowNumber int, cells []*table.RCell) {
 			if rowNumber == -1 {
-				for i := 4; i < t.ColumnCount; i += 2 {
-					requireMemberValidity(members, cells[i].Value, (i-4)/2, t.SheetName, t.GetCell(rowNumber, i))
+				for i := 0; i < members.Count(); i++ {
+					c := layout.fullColumn(i, shareWeightHeader)
+					errs.Add(checkMemberValidity(members, cells[c].Value, i, t.SheetName, t.GetCell(rowNumber, c)))
 				}
 				return
 			}
@@ -613,16 +805,16 @@ func loadExpenses(t *table.Table, members *store.MemberStore) []*model.Expense {
 				return
 			}
 
-			theTime, timeErr := model.ParseTime(cells[0].Value)
-			fatalIfNotNil(log.CellErrorOf(timeErr, t.SheetName, t.GetCell(rowNumber, 0)))
+			errCount := errs.Len()
+			theTime, timeErr := model.ParseTime(cells[timeColumn].Value)
+			errs.Add(log.CellErrorOf(timeErr, t.SheetName, t.GetCell(rowNumber, timeColumn)))
 
-			title := cells[1].Value
+			title := cells[titleColumn].Value
 
-			payer := cells[2].Value
-			requireMemberPresence(members, payer, t.SheetName, t.GetCell(rowNumber, 2))
+			payer := cells[payerColumn].Value
 
-			amount, amountErr := model.ParseAmount(cells[3].Value)
-			fatalIfNotNil(log.CellErrorOf(amountErr, t.SheetName, t.GetCell(rowNumber, 3)))
+			amount, amountErr := model.ParseAmount(cells[amountColumn].Value)
+			errs.Add(log.CellErrorOf(amountErr, t.SheetName, t.GetCell(rowNumber, amountColumn)))
 
 			ex := &model.Expense{
 				Title:     title,
@@ -631,76 +823,128 @@ func loadExpenses(t *table.Table, members *store.MemberStore) []*model.Expense {
 				Amount:    amount,
 			}
 
-			var shares []model.Share
-			for i := 4; i < t.ColumnCount; i += 2 {
-				weight, err := model.ParseShareWeight(cells[i].Value)
-				fatalIfNotNil(log.CellErrorOf(err, t.SheetName, t.GetCell(rowNumber, i)))
-
-				memberName := members.RequireMemberByIndex((i - 4) / 2).Name
-				shares = append(shares, model.Share{
-					MemberName:  memberName,
-					ShareWeight: weight,
-				})
+			if currencyColumn != -1 {
+				ex.Currency = strings.TrimSpace(cells[currencyColumn].Value)
+				if timeErr == nil {
+					errs.Add(checkRate(rates, ex.Currency, theTime, t.SheetName, t.GetCell(rowNumber, currencyColumn)))
+				}
+			}
+
+			ex.SplitMode = model.SplitByWeight
+			if splitColumn != -1 {
+				mode, err := model.ParseSplitMode(cells[splitColumn].Value)
+				errs.Add(log.CellErrorOf(err, t.SheetName, t.GetCell(rowNumber, splitColumn)))
+				ex.SplitMode = mode
 			}
 
+			var shares []model.Share
+			for i := 0; i < members.Count(); i++ {
+				c := layout.fullColumn(i, shareWeightHeader)
+				share := model.Share{
+					MemberName: members.RequireMemberByIndex(i).Name,
+				}
+				var err error
+				if ex.UsesShareValues() {
+					share.ShareValue, err = model.ParseShareValue(cells[c].Value)
+				} else {
+					share.ShareWeight, err = model.ParseShareWeight(cells[c].Value)
+				}
+				errs.Add(log.CellErrorOf(err, t.SheetName, t.GetCell(rowNumber, c)))
+
+				if pc := layout.fullColumn(i, paidHeader); pc != -1 {
+					share.Paid, err = model.ParseAmount(cells[pc].Value)
+					errs.Add(log.CellErrorOf(err, t.SheetName, t.GetCell(rowNumber, pc)))
+				}
+				shares = append(shares, share)
+			}
 			ex.Shares = shares
+
+			if payer != "" || !ex.HasPaidPortions() {
+				errs.Add(checkMemberPresence(members, payer, t.SheetName, t.GetCell(rowNumber, payerColumn)))
+			}
+
+			// the checks below are meaningless if the values could not be parsed
+			if errs.Len() > errCount {
+				return
+			}
+			errs.Add(log.CellErrorOf(ex.ValidateShares(), t.SheetName, t.GetCell(rowNumber, amountColumn)))
+			errs.Add(log.CellErrorOf(ex.ValidatePaidPortions(), t.SheetName, t.GetCell(rowNumber, amountColumn)))
+			if payerIndex := members.GetIndexByName(payer); ex.HasPaidPortions() && payerIndex != -1 && ex.Shares[payerIndex].Paid.IsZero() {
+				errs.Add(log.CellErrorOf(fmt.Errorf("payer %q has not paid any portion of the amount", payer),
+					t.SheetName, t.GetCell(rowNumber, payerColumn)))
+			}
+
 			expenses = append(expenses, ex)
 		},
 		IncludeHeader:   true,
 		UnknownRowCount: true,
 	})
 
-	return expenses
+	errs.Add(err)
+
+	return expenses, errs.Err()
 }
 
-func loadTransactions(t *table.Table, members *store.MemberStore) []*model.Transaction {
+// loadTransactions reads the transactions. It does not stop at the first invalid cell; all the errors are returned together.
+func loadTransactions(t *table.Table, members *store.MemberStore, rates *store.RateStore) ([]*model.Transaction, error) {
 
+	var errs log.ErrorList
 	var transactions []*model.Transaction
-	t.ReadRows(table.ReadRowsParams{
+	err := t.ReadRows(table.ReadRowsParams{
 		RowReader: func(rowNumber int, cells []*table.RCell) {
-			theTime, err := model.ParseTime(cells[0].Value)
-			fatalIfNotNil(log.CellErrorOf(err, t.SheetName, t.GetCell(rowNumber, 0)))
+			theTime, timeErr := model.ParseTime(cells[0].Value)
+			errs.Add(log.CellErrorOf(timeErr, t.SheetName, t.GetCell(rowNumber, 0)))
 
 			receiver := cells[1].Value
-			requireMemberPresence(members, receiver, t.SheetName, t.GetCell(rowNumber, 1))
+			errs.Add(checkMemberPresence(members, receiver, t.SheetName, t.GetCell(rowNumber, 1)))
 
 			payer := cells[2].Value
-			requireMemberPresence(members, payer, t.SheetName, t.GetCell(rowNumber, 2))
+			errs.Add(checkMemberPresence(members, payer, t.SheetName, t.GetCell(rowNumber, 2)))
 
 			amount, err := model.ParseAmount(cells[3].Value)
-			fatalIfNotNil(log.CellErrorOf(err, t.SheetName, t.GetCell(rowNumber, 3)))
+			errs.Add(log.CellErrorOf(err, t.SheetName, t.GetCell(rowNumber, 3)))
+
+			currency := strings.TrimSpace(cells[4].Value)
+			if timeErr == nil {
+				errs.Add(checkRate(rates, currency, theTime, t.SheetName, t.GetCell(rowNumber, 4)))
+			}
 
 			transactions = append(transactions, &model.Transaction{
 				Time:         theTime,
 				ReceiverName: receiver,
 				PayerName:    payer,
 				Amount:       amount,
+				Currency:     currency,
 			})
 		},
 		IncludeHeader:   false,
 		UnknownRowCount: true,
 	})
 
-	return transactions
+	errs.Add(err)
+
+	return transactions, errs.Err()
 }
 
-func loadBaseState(t *table.Table, members *store.MemberStore) [][]model.Amount {
+// loadBaseState reads the base state. It does not stop at the first invalid cell; all the errors are returned together.
+func loadBaseState(t *table.Table, members *store.MemberStore) ([][]model.Amount, error) {
 
-	baseState := emptyMatrix(members.Count())
-	t.ReadRows(table.ReadRowsParams{
+	var errs log.ErrorList
+	baseState := ledger.EmptyMatrix(members.Count())
+	err := t.ReadRows(table.ReadRowsParams{
 		RowCount: members.Count(),
 		RowReader: func(rowNumber int, cells []*table.RCell) {
 			if rowNumber == -1 {
 				for i := 0; i < members.Count(); i++ {
-					requireMemberValidity(members, cells[i+1].Value, i, t.SheetName, t.GetCell(rowNumber, i+1))
+					errs.Add(checkMemberValidity(members, cells[i+1].Value, i, t.SheetName, t.GetCell(rowNumber, i+1)))
 				}
 				return
 			}
-			requireMemberValidity(members, cells[0].Value, rowNumber, t.SheetName, t.GetCell(rowNumber, 0))
+			errs.Add(checkMemberValidity(members, cells[0].Value, rowNumber, t.SheetName, t.GetCell(rowNumber, 0)))
 
 			for i := 0; i < members.Count(); i++ {
 				amount, err := model.ParseAmount(cells[i+1].Value)
-				fatalIfNotNil(log.CellErrorOf(err, t.SheetName, t.GetCell(rowNumber, i+1)))
+				errs.Add(log.CellErrorOf(err, t.SheetName, t.GetCell(rowNumber, i+1)))
 				baseState[rowNumber][i] = amount
 			}
 		},
@@ -708,56 +952,30 @@ func loadBaseState(t *table.Table, members *store.MemberStore) [][]model.Amount
 		UnknownRowCount: false,
 	})
 
-	return baseState
-}
-
-func loadMetadata(t *table.Table) *style.Theme {
-	var theme *style.Theme
-	t.ReadRows(table.ReadRowsParams{
-		RowCount: 1,
-		RowReader: func(rowNumber int, cells []*table.RCell) {
-			if rowNumber == 0 {
-				theme = style.ThemeFromCode(cells[0].Value)
-			}
-		},
-		IncludeHeader: false,
-	})
+	errs.Add(err)
 
-	return theme
+	return baseState, errs.Err()
 }
 
-func requireMemberValidity(members *store.MemberStore, memberName string, index int, sheetName, cell string) {
+func checkMemberValidity(members *store.MemberStore, memberName string, index int, sheetName, cell string) error {
 	if !members.IsValid(memberName, index) {
-		log.FatalErrorByCaller(log.CellErrorOf(fmt.Errorf("found no member with name %q and index %d", memberName, index), sheetName, cell))
+		return log.CellErrorOf(fmt.Errorf("found no member with name %q and index %d", memberName, index), sheetName, cell)
 	}
+	return nil
 }
 
-func requireMemberPresence(members *store.MemberStore, memberName string, sheetName, cell string) {
+func checkMemberPresence(members *store.MemberStore, memberName string, sheetName, cell string) error {
 	if !members.IsPresent(memberName) {
-		log.FatalErrorByCaller(log.CellErrorOf(fmt.Errorf("found no member with name %q", memberName), sheetName, cell))
-	}
-}
-
-func fatalIfNotNil(err error) {
-	if err != nil {
-		log.FatalErrorByCaller(err)
-	}
-}
-
-func emptyMatrix(length int) [][]model.Amount {
-	result := make([][]model.Amount, length)
-	for i := range result {
-		result[i] = make([]model.Amount, length)
+		return log.CellErrorOf(fmt.Errorf("found no member with name %q", memberName), sheetName, cell)
 	}
-	return result
+	return nil
 }
 
-func copyMatrix(source [][]model.Amount) [][]model.Amount {
-	result := emptyMatrix(len(source))
-	for i := range source {
-		copy(result[i], source[i])
+func checkRate(rates *store.RateStore, currency string, t model.Time, sheetName, cell string) error {
+	if _, err := rates.Convert(model.AmountZero(), currency, t); err != nil {
+		return log.CellErrorOf(err, sheetName, cell)
 	}
-	return result
+	return nil
 }
 
 func newInt(n int) *int {
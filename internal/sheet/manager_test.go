@@ -0,0 +1,231 @@
+package sheet_test
+
+import (
+	"errors"
+	"github.com/MeysamBavi/group-expense-manager/internal/log"
+	"github.com/MeysamBavi/group-expense-manager/internal/model"
+	"github.com/MeysamBavi/group-expense-manager/internal/sheet"
+	"github.com/MeysamBavi/group-expense-manager/internal/sheet/style"
+	"github.com/MeysamBavi/group-expense-manager/internal/storage/storagetest"
+	"github.com/MeysamBavi/group-expense-manager/pkg/ledger"
+	assert2 "github.com/stretchr/testify/assert"
+	"github.com/xuri/excelize/v2"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// newManager creates a new spreadsheet with the given members.
+func newManager(t *testing.T, names ...string) *sheet.Manager {
+	manager, err := sheet.NewManager(storagetest.Members(t, names...), style.BlueTheme(), sheet.DefaultSettings())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return manager
+}
+
+// saveSpreadsheet saves a spreadsheet to a temporary file and returns its path.
+func saveSpreadsheet(t *testing.T, manager *sheet.Manager) string {
+	fileName := filepath.Join(t.TempDir(), "sheet.xlsx")
+	if err := manager.SaveAs(fileName); err != nil {
+		t.Fatal(err)
+	}
+	return fileName
+}
+
+// reloadSpreadsheet saves a spreadsheet and loads it again.
+func reloadSpreadsheet(t *testing.T, manager *sheet.Manager) *sheet.Manager {
+	manager, err := sheet.LoadManager(saveSpreadsheet(t, manager))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return manager
+}
+
+// createSpreadsheet creates a new spreadsheet with three members and returns its path.
+func createSpreadsheet(t *testing.T) string {
+	return saveSpreadsheet(t, newManager(t, "alice", "bob", "carol"))
+}
+
+// editSpreadsheet opens a spreadsheet with excelize, applies the edit and saves it.
+func editSpreadsheet(t *testing.T, fileName string, edit func(file *excelize.File) error) {
+	file, err := excelize.OpenFile(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = edit(file); err != nil {
+		t.Fatal(err)
+	}
+	if err = file.Save(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadManager(t *testing.T) {
+	assert := assert2.New(t)
+
+	fileName := createSpreadsheet(t)
+	editSpreadsheet(t, fileName, func(file *excelize.File) error {
+		return file.SetSheetRow("expenses", "A4", &[]any{"2023/1/1 12:00", "dinner", "alice", 90, "", "", 1, nil, nil, 1, nil, nil, 1})
+	})
+
+	manager, err := sheet.LoadManager(fileName)
+	if !assert.NoError(err) {
+		return
+	}
+	assert.Equal([][]string{
+		{"0", "0", "0"},
+		{"30", "0", "0"},
+		{"30", "0", "0"},
+	}, debtsOf(t, manager))
+	assert.NoError(manager.SaveAs(fileName))
+}
+
+func TestManager_ForeignCurrencyExpense(t *testing.T) {
+	assert := assert2.New(t)
+
+	settings := sheet.DefaultSettings()
+	settings.BaseCurrency = "USD"
+	manager, err := sheet.NewManager(storagetest.Members(t, "alice", "bob", "carol"), style.BlueTheme(), settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rates := manager.Rates()
+	if err := rates.AddRate("EUR", model.TimeOfGregorian(time.Time{}), model.AmountOf(2)); err != nil {
+		t.Fatal(err)
+	}
+	if err := manager.ReplaceRecords(rates, nil, nil, ledger.EmptyMatrix(3)); err != nil {
+		t.Fatal(err)
+	}
+	err = manager.AddExpense(&model.Expense{Title: "dinner", PayerName: "alice", Amount: model.AmountOf(30),
+		Currency: "EUR", Shares: equalShares("alice", "bob", "carol")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fileName := saveSpreadsheet(t, manager)
+
+	file, err := excelize.OpenFile(fileName)
+	if !assert.NoError(err) {
+		return
+	}
+	// the share amount of bob is 10 EUR, but the debt to alice is 20 USD
+	formula, err := file.GetCellFormula("expenses", "K4")
+	assert.NoError(err)
+	assert.Contains(formula, "*D4")
+	help, err := file.GetCellValue("expenses", "A1")
+	assert.NoError(err)
+	assert.Contains(help, "in the row's currency")
+
+	manager, err = sheet.LoadManager(fileName)
+	if !assert.NoError(err) {
+		return
+	}
+	assert.Equal([][]string{
+		{"0", "0", "0"},
+		{"20", "0", "0"},
+		{"20", "0", "0"},
+	}, debtsOf(t, manager))
+}
+
+func TestLoadManager_InvalidCells(t *testing.T) {
+	assert := assert2.New(t)
+
+	fileName := createSpreadsheet(t)
+	editSpreadsheet(t, fileName, func(file *excelize.File) error {
+		err := file.SetSheetRow("expenses", "A4", &[]any{"not a time", "dinner", "dave", "ninety", "", "", 1})
+		if err != nil {
+			return err
+		}
+		err = file.SetSheetRow("transactions", "A3", &[]any{"2023/1/1 12:00", "eve", "bob", 10})
+		if err != nil {
+			return err
+		}
+		return file.SetCellValue("base state", "C3", "a lot")
+	})
+
+	_, err := sheet.LoadManager(fileName)
+	var validationErr *log.ValidationError
+	if !assert.ErrorAs(err, &validationErr) {
+		return
+	}
+
+	var cells []string
+	for _, err := range validationErr.Errors {
+		var cellErr *log.CellError
+		if assert.ErrorAs(err, &cellErr) {
+			cells = append(cells, cellErr.SheetName+"!"+cellErr.CellName)
+		}
+	}
+	assert.ElementsMatch([]string{"expenses!A4", "expenses!D4", "expenses!C4", "transactions!B3", "base state!C3"}, cells)
+}
+
+func TestLoadManager_SettlementConstraints(t *testing.T) {
+	assert := assert2.New(t)
+
+	fileName := createSpreadsheet(t)
+	editSpreadsheet(t, fileName, func(file *excelize.File) error {
+		err := file.SetSheetCol("settlement constraints", "B2", &[]any{"Bob", "5"})
+		if err != nil {
+			return err
+		}
+		return file.SetSheetRow("settlement constraints", "A7", &[]any{"carol", "alice"})
+	})
+
+	manager, err := sheet.LoadManager(fileName)
+	if !assert.NoError(err) {
+		return
+	}
+	assert.Equal(&ledger.SettlementConstraints{
+		Treasurer:     "bob",
+		Blocked:       []ledger.BlockedPair{{PayerName: "carol", ReceiverName: "alice"}},
+		MinimumAmount: model.AmountOf(5),
+	}, manager.SettlementConstraints())
+
+	editSpreadsheet(t, fileName, func(file *excelize.File) error {
+		err := file.SetSheetCol("settlement constraints", "B2", &[]any{"dave", "-5"})
+		if err != nil {
+			return err
+		}
+		return file.SetSheetRow("settlement constraints", "A8", &[]any{"bob", "bob"})
+	})
+	_, err = sheet.LoadManager(fileName)
+	var validationErr *log.ValidationError
+	if assert.ErrorAs(err, &validationErr) {
+		assert.Len(validationErr.Errors, 3)
+	}
+}
+
+func TestLoadManager_CorruptedFile(t *testing.T) {
+	assert := assert2.New(t)
+
+	fileName := createSpreadsheet(t)
+	editSpreadsheet(t, fileName, func(file *excelize.File) error {
+		return file.DeleteSheet("expenses")
+	})
+	_, err := sheet.LoadManager(fileName)
+	assert.Error(err)
+
+	fileName = createSpreadsheet(t)
+	editSpreadsheet(t, fileName, func(file *excelize.File) error {
+		return file.SetCellValue("expenses", "D2", "Cost")
+	})
+	_, err = sheet.LoadManager(fileName)
+	var sheetErr *log.SheetError
+	assert.ErrorAs(err, &sheetErr)
+
+	fileName = createSpreadsheet(t)
+	editSpreadsheet(t, fileName, func(file *excelize.File) error {
+		return file.SetSheetRow("metadata", "A2", &[]any{"color", "blue"})
+	})
+	_, err = sheet.LoadManager(fileName)
+	assert.Error(err)
+
+	fileName = filepath.Join(t.TempDir(), "not-a-sheet.xlsx")
+	assert.NoError(os.WriteFile(fileName, []byte("plain text"), 0644))
+	_, err = sheet.LoadManager(fileName)
+	assert.Error(err)
+
+	_, err = sheet.LoadManager(filepath.Join(t.TempDir(), "missing.xlsx"))
+	assert.True(errors.Is(err, os.ErrNotExist))
+}
@@ -0,0 +1,507 @@
+package sheet
+
+import (
+	"errors"
+	"fmt"
+	"github.com/MeysamBavi/group-expense-manager/internal/model"
+	"github.com/MeysamBavi/group-expense-manager/internal/sheet/style"
+	"github.com/MeysamBavi/group-expense-manager/internal/sheet/table"
+	"github.com/MeysamBavi/group-expense-manager/pkg/ledger"
+	"strings"
+)
+
+// AddMember appends a new member to the spreadsheet. The new member gets a zero share weight in all the existing
+// expenses and has no debts in the base state.
+func (m *Manager) AddMember(member *model.Member) error {
+	err := m.members.AddMember(member)
+	if err != nil {
+		return err
+	}
+
+	memberIndex := m.MembersCount() - 1
+	setTablesExceptMembers(m)
+
+	for _, expense := range m.expenses {
+		expense.Shares = append(expense.Shares, model.Share{
+			MemberName:  member.Name,
+			ShareWeight: 0,
+		})
+	}
+	m.baseState = expandMatrix(m.baseState)
+
+	err = initializeMembers(m)
+	if err != nil {
+		return err
+	}
+	err = m.writeExpensesMemberColumns(memberIndex)
+	if err != nil {
+		return err
+	}
+	for i := 0; i < memberIndex; i++ {
+		err = m.writeShareAmounts(i)
+		if err != nil {
+			return err
+		}
+	}
+	err = m.writeBaseState()
+	if err != nil {
+		return err
+	}
+
+	return m.UpdateDebtors()
+}
+
+// RemoveMember removes a member from the spreadsheet. The member's net balance should be zero, unless transferTo names
+// another member who takes it over.
+// Without transferTo, the member should not take part in any expense or transaction, nor have debts in the base state;
+// Deactivating keeps such a member's history instead. With transferTo, the records are kept and the member's part in
+// them is given to the other member: they become the payer of the member's expenses and transactions, their shares
+// and paid portions grow by the member's, and the member's debts in the base state are added to theirs.
+// The member's share columns are removed from all expenses, and the net balance of every other member stays the same.
+func (m *Manager) RemoveMember(name, transferTo string) error {
+	memberIndex := m.members.GetIndexByName(name)
+	if memberIndex == -1 {
+		return fmt.Errorf("found no member with name %q", name)
+	}
+	member := m.members.RequireMemberByIndex(memberIndex)
+
+	transferIndex := -1
+	if transferTo != "" {
+		transferIndex = m.members.GetIndexByName(transferTo)
+		if transferIndex == -1 {
+			return fmt.Errorf("found no member with name %q", transferTo)
+		}
+		if transferIndex == memberIndex {
+			return errors.New("cannot transfer the balance of a member to themselves")
+		}
+	}
+
+	if m.MembersCount() <= 2 {
+		return errors.New("number of members should stay more than 1")
+	}
+
+	err := m.calculateDebtMatrix()
+	if err != nil {
+		return err
+	}
+	oldBalances := ledger.ComputeBalances(m.debtMatrix)
+	if transferIndex == -1 {
+		if !oldBalances[memberIndex].IsZero() {
+			return fmt.Errorf("member %q has a non-zero balance of %s; settle up first or transfer the balance to another member",
+				member.Name, oldBalances[memberIndex])
+		}
+		if err = m.checkNoRecordsOf(memberIndex); err != nil {
+			return err
+		}
+	}
+
+	expenses, err := m.transferExpenses(memberIndex, transferIndex)
+	if err != nil {
+		return err
+	}
+	transactions, err := m.transferTransactions(memberIndex, transferIndex)
+	if err != nil {
+		return err
+	}
+	baseState := ledger.CopyMatrix(m.baseState)
+	if transferIndex != -1 {
+		for i := range baseState {
+			baseState[transferIndex][i] = baseState[transferIndex][i].Add(baseState[memberIndex][i])
+			baseState[i][transferIndex] = baseState[i][transferIndex].Add(baseState[i][memberIndex])
+		}
+		// the debts between the two members are now debts of a member to themselves
+		baseState[transferIndex][transferIndex] = model.AmountZero()
+	}
+	baseState = shrinkMatrix(baseState, memberIndex)
+
+	err = m.checkBalancesAfterRemoval(memberIndex, transferIndex, oldBalances, expenses, transactions, baseState)
+	if err != nil {
+		return err
+	}
+
+	m.expenses, m.transactions, m.baseState = expenses, transactions, baseState
+	m.removeFromSettlementConstraints(memberIndex)
+	err = m.members.RemoveMember(member.Name)
+	if err != nil {
+		return err
+	}
+	setTablesExceptMembers(m)
+
+	return m.rewriteSheets()
+}
+
+// checkNoRecordsOf returns an error if a member takes part in an expense or a transaction, or has debts in the base
+// state. The dummy rows of the expenses and transactions sheets are not records.
+func (m *Manager) checkNoRecordsOf(memberIndex int) error {
+	template, _ := m.templateExpense()
+	expenses := 0
+	for _, expense := range m.expenses {
+		if expense != template && m.takesPart(expense, memberIndex) {
+			expenses++
+		}
+	}
+	transactions := 0
+	for _, transaction := range m.transactions {
+		if !transaction.Amount.IsZero() && (m.members.GetIndexByName(transaction.ReceiverName) == memberIndex ||
+			m.members.GetIndexByName(transaction.PayerName) == memberIndex) {
+			transactions++
+		}
+	}
+	hasDebts := false
+	for i := range m.baseState {
+		if !m.baseState[memberIndex][i].IsZero() || !m.baseState[i][memberIndex].IsZero() {
+			hasDebts = true
+		}
+	}
+
+	var records []string
+	if expenses > 0 {
+		records = append(records, fmt.Sprintf("%d expenses", expenses))
+	}
+	if transactions > 0 {
+		records = append(records, fmt.Sprintf("%d transactions", transactions))
+	}
+	if hasDebts {
+		records = append(records, "debts in the base state")
+	}
+	if len(records) == 0 {
+		return nil
+	}
+	return fmt.Errorf("member %q still has %s; transfer them to another member or deactivate the member instead",
+		m.members.RequireMemberByIndex(memberIndex).Name, strings.Join(records, ", "))
+}
+
+// takesPart reports whether a member is the payer of an expense, has paid a portion of it or has a share in it.
+func (m *Manager) takesPart(expense *model.Expense, memberIndex int) bool {
+	share := expense.Shares[memberIndex]
+	return m.members.GetIndexByName(expense.PayerName) == memberIndex || share.ShareWeight != 0 ||
+		!share.ShareValue.IsZero() || !share.Paid.IsZero()
+}
+
+// transferExpenses returns copies of the expenses without the share of a member. If transferIndex is not -1, the
+// member's share and paid portion are added to those of the member at transferIndex, who also becomes the payer in
+// place of the member.
+// If that changes how the amount of an expense in the base currency is rounded, the expense is split by the exact
+// amounts of the shares before the transfer.
+func (m *Manager) transferExpenses(memberIndex, transferIndex int) ([]*model.Expense, error) {
+	l := m.Ledger()
+	template, _ := m.templateExpense()
+	replacement := m.members.RequireMemberByIndex(otherIndex(memberIndex, -1)).Name
+	if transferIndex != -1 {
+		replacement = m.members.RequireMemberByIndex(transferIndex).Name
+	}
+
+	expenses := make([]*model.Expense, 0, len(m.expenses))
+	for _, expense := range m.expenses {
+		// the weights of the dummy row are defaults for new expenses, so they are not added up
+		isRecord := expense != template
+		transferred := *expense
+		transferred.Shares = make([]model.Share, 0, len(expense.Shares)-1)
+		for i, share := range expense.Shares {
+			if i == memberIndex {
+				continue
+			}
+			if i == transferIndex && isRecord {
+				removed := expense.Shares[memberIndex]
+				share.ShareWeight += removed.ShareWeight
+				share.ShareValue = share.ShareValue.Add(removed.ShareValue)
+				share.Paid = share.Paid.Add(removed.Paid)
+			}
+			transferred.Shares = append(transferred.Shares, share)
+		}
+		if m.members.GetIndexByName(expense.PayerName) == memberIndex {
+			transferred.PayerName = replacement
+		}
+
+		if isRecord && transferIndex != -1 && !expense.Amount.IsZero() && m.takesPart(expense, memberIndex) {
+			err := m.keepShareAmounts(l, expense, &transferred, memberIndex, transferIndex)
+			if err != nil {
+				return nil, fmt.Errorf("expense %q: %w", expense.Title, err)
+			}
+		}
+		expenses = append(expenses, &transferred)
+	}
+	return expenses, nil
+}
+
+// keepShareAmounts splits a transferred expense by the exact share amounts of the original expense, if the transfer
+// changes them. Only the expenses in the base currency can be changed this way.
+func (m *Manager) keepShareAmounts(l *ledger.Ledger, expense, transferred *model.Expense, memberIndex, transferIndex int) error {
+	before, err := ledger.ComputeShareAmounts(l, expense)
+	if err != nil {
+		return err
+	}
+	after, err := ledger.ComputeShareAmounts(l, transferred)
+	if err != nil {
+		return err
+	}
+
+	expected := make([]model.Amount, 0, len(after))
+	for i, amount := range before {
+		if i == memberIndex {
+			continue
+		}
+		if i == transferIndex {
+			amount = amount.Add(before[memberIndex])
+		}
+		expected = append(expected, amount)
+	}
+	changed := false
+	sum := model.AmountZero()
+	for i := range expected {
+		changed = changed || !expected[i].Sub(after[i]).IsZero()
+		sum = sum.Add(expected[i])
+	}
+	if !changed || !m.rates.IsBaseCurrency(transferred.Currency) || !sum.Sub(transferred.Amount).IsZero() {
+		return nil
+	}
+
+	transferred.SplitMode = model.SplitByExact
+	for i := range transferred.Shares {
+		transferred.Shares[i].ShareWeight = 0
+		transferred.Shares[i].ShareValue = expected[i]
+	}
+	return nil
+}
+
+// transferTransactions returns copies of the transactions in which the member at transferIndex, if it is not -1,
+// replaces the receiver or the payer that is the member at memberIndex.
+// Transactions with zero amount, like the dummy row of the transactions sheet, are not records; another member
+// replaces the member in them if the member at transferIndex can not.
+func (m *Manager) transferTransactions(memberIndex, transferIndex int) ([]*model.Transaction, error) {
+	transactions := make([]*model.Transaction, 0, len(m.transactions))
+	for _, transaction := range m.transactions {
+		transferred := *transaction
+		receiverIndex := m.members.GetIndexByName(transaction.ReceiverName)
+		payerIndex := m.members.GetIndexByName(transaction.PayerName)
+		if receiverIndex == memberIndex || payerIndex == memberIndex {
+			otherPartyIndex := receiverIndex + payerIndex - memberIndex
+			var replacementIndex int
+			switch {
+			case transferIndex != -1 && otherPartyIndex != transferIndex:
+				replacementIndex = transferIndex
+			case transferIndex == -1 || transaction.Amount.IsZero():
+				replacementIndex = otherIndex(memberIndex, otherPartyIndex)
+			default:
+				return nil, fmt.Errorf("transaction of %s between %q and %q would become a payment of %q to themselves; transfer to another member",
+					transaction.Time, transaction.PayerName, transaction.ReceiverName, m.members.RequireMemberByIndex(transferIndex).Name)
+			}
+			replacement := m.members.RequireMemberByIndex(replacementIndex).Name
+			if receiverIndex == memberIndex {
+				transferred.ReceiverName = replacement
+			} else {
+				transferred.PayerName = replacement
+			}
+		}
+		transactions = append(transactions, &transferred)
+	}
+	return transactions, nil
+}
+
+// checkBalancesAfterRemoval returns an error if removing the member at memberIndex with the given records changes the
+// net balance of another member, which can happen when the shares of an expense are rounded differently.
+// The member at transferIndex, if it is not -1, should have the sum of both balances.
+func (m *Manager) checkBalancesAfterRemoval(memberIndex, transferIndex int, oldBalances []model.Amount,
+	expenses []*model.Expense, transactions []*model.Transaction, baseState [][]model.Amount) error {
+	l := m.Ledger()
+	members := make([]*model.Member, 0, len(l.Members)-1)
+	members = append(members, l.Members[:memberIndex]...)
+	l.Members = append(members, l.Members[memberIndex+1:]...)
+	if _, ok := m.templateExpense(); ok {
+		expenses = expenses[1:]
+	}
+	l.Expenses, l.Transactions, l.BaseState = expenses, transactions, baseState
+
+	debtMatrix, err := ledger.ComputeDebtMatrix(l)
+	if err != nil {
+		return err
+	}
+	newBalances := ledger.ComputeBalances(debtMatrix)
+	for i, oldBalance := range oldBalances {
+		if i == memberIndex {
+			continue
+		}
+		if i == transferIndex {
+			oldBalance = oldBalance.Add(oldBalances[memberIndex])
+		}
+		newIndex := i
+		if i > memberIndex {
+			newIndex--
+		}
+		if !oldBalance.Sub(newBalances[newIndex]).IsZero() {
+			return fmt.Errorf("removing %q would change the balance of %q from %s to %s, because of the rounding of the shares of its expenses",
+				m.members.RequireMemberByIndex(memberIndex).Name, l.Members[newIndex].Name, oldBalance, newBalances[newIndex])
+		}
+	}
+	return nil
+}
+
+// DeactivateMember marks a member as inactive. The member's history is kept, but their share columns are hidden
+// and their share weight in the dummy row of the expenses sheet is set to zero.
+func (m *Manager) DeactivateMember(name string) error {
+	memberIndex := m.members.GetIndexByName(name)
+	if memberIndex == -1 {
+		return fmt.Errorf("found no member with name %q", name)
+	}
+
+	member := m.members.RequireMemberByIndex(memberIndex)
+	if member.Deactivated {
+		return fmt.Errorf("member %q is already deactivated", member.Name)
+	}
+	member.Deactivated = true
+
+	if template, ok := m.templateExpense(); ok {
+		template.Shares[memberIndex].ShareWeight = 0
+	}
+
+	err := initializeMembers(m)
+	if err != nil {
+		return err
+	}
+
+	return m.writeExpenses()
+}
+
+// RenameMember changes the name of a member in all the sheets.
+func (m *Manager) RenameMember(oldName, newName string) error {
+	memberIndex := m.members.GetIndexByName(oldName)
+	if memberIndex == -1 {
+		return fmt.Errorf("found no member with name %q", oldName)
+	}
+
+	var names []*string
+	collect := func(name *string) {
+		if m.members.GetIndexByName(*name) == memberIndex {
+			names = append(names, name)
+		}
+	}
+	for _, expense := range m.expenses {
+		collect(&expense.PayerName)
+		for i := range expense.Shares {
+			collect(&expense.Shares[i].MemberName)
+		}
+	}
+	for _, transaction := range m.transactions {
+		collect(&transaction.ReceiverName)
+		collect(&transaction.PayerName)
+	}
+	collect(&m.constraints.Treasurer)
+	for i := range m.constraints.Blocked {
+		collect(&m.constraints.Blocked[i].PayerName)
+		collect(&m.constraints.Blocked[i].ReceiverName)
+	}
+	for i := range m.constraints.Preferred {
+		collect(&m.constraints.Preferred[i].PayerName)
+		collect(&m.constraints.Preferred[i].ReceiverName)
+	}
+
+	err := m.members.RenameMember(oldName, newName)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		*name = newName
+	}
+
+	return m.rewriteSheets()
+}
+
+// rewriteSheets rewrites the members, expenses, transactions, base state and settlement constraints sheets and updates
+// the debts.
+func (m *Manager) rewriteSheets() error {
+	err := initializeMembers(m)
+	if err != nil {
+		return err
+	}
+	for _, write := range []func() error{m.writeExpenses, m.writeTransactions, m.writeBaseState, m.writeSettlementConstraints,
+		m.UpdateDebtors} {
+		if err = write(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// templateExpense returns the dummy row of the expenses sheet, if it is not used as a real expense.
+func (m *Manager) templateExpense() (*model.Expense, bool) {
+	if len(m.expenses) == 0 || !m.expenses[0].Amount.IsZero() {
+		return nil, false
+	}
+	return m.expenses[0], true
+}
+
+// writeExpensesMemberColumns writes the header and the cells of a member in the expenses sheet.
+func (m *Manager) writeExpensesMemberColumns(memberIndex int) error {
+	t := newExpensesMemberTable(m.file, m.expensesLayout, memberIndex)
+	return t.WriteRows(table.WriteRowsParams{
+		RowCount: len(m.expenses) + 1,
+		HeaderWriter: func(cells []*table.WCell, mergeCount *int) {
+			*mergeCount = t.ColumnCount
+			cells[0].Value = m.members.RequireMemberByIndex(memberIndex).Name
+		},
+		RowWriter: func(rowNumber int, cells []*table.WCell) {
+			m.writeExpenseMemberCells(rowNumber, memberIndex, cells)
+		},
+		RowStyler: func(row int) (int, bool) {
+			if row == -1 {
+				return m.getStyle(headerBoxStyle), true
+			}
+			if row == 0 {
+				return m.getStyle(secondHeaderBoxStyle), true
+			}
+
+			return 0, false
+		},
+		ColumnWidth: 11,
+		ConditionalStyles: style.Alternate(m.getStyle(alternate0Style), m.getStyle(alternate1Style), m.getStyle(alternate2Style)).
+			WithStart(1, 0).
+			WithEnd(len(m.expenses), t.ColumnCount-1).
+			Build(),
+	})
+}
+
+// writeShareAmounts rewrites the Share Amount formulas of a member in all the expenses.
+func (m *Manager) writeShareAmounts(memberIndex int) error {
+	t := newExpensesShareAmountTable(m.file, m.expensesLayout, memberIndex)
+	return t.WriteRows(table.WriteRowsParams{
+		RowCount: len(m.expenses) + 1,
+		RowWriter: func(rowNumber int, cells []*table.WCell) {
+			if rowNumber == 0 {
+				cells[0].Value = shareAmountHeader
+				return
+			}
+			cells[0].Formula = m.shareAmountFormula(rowNumber, memberIndex)
+			cells[0].Style = newInt(m.getStyle(moneyStyle))
+		},
+	})
+}
+
+func expandMatrix(source [][]model.Amount) [][]model.Amount {
+	result := ledger.EmptyMatrix(len(source) + 1)
+	for i := range source {
+		copy(result[i], source[i])
+	}
+	return result
+}
+
+func shrinkMatrix(source [][]model.Amount, index int) [][]model.Amount {
+	result := ledger.EmptyMatrix(len(source) - 1)
+	for r := range result {
+		sr := r
+		if r >= index {
+			sr++
+		}
+		copy(result[r], source[sr][:index])
+		copy(result[r][index:], source[sr][index+1:])
+	}
+	return result
+}
+
+// otherIndex returns the smallest member index which is not equal to any of the given indices.
+func otherIndex(a, b int) int {
+	for i := 0; ; i++ {
+		if i != a && i != b {
+			return i
+		}
+	}
+}
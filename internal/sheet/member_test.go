@@ -0,0 +1,246 @@
+package sheet_test
+
+import (
+	"github.com/MeysamBavi/group-expense-manager/internal/model"
+	"github.com/MeysamBavi/group-expense-manager/internal/sheet"
+	"github.com/MeysamBavi/group-expense-manager/pkg/ledger"
+	assert2 "github.com/stretchr/testify/assert"
+	"github.com/xuri/excelize/v2"
+	"testing"
+)
+
+// equalShares returns a share weight of one for each member.
+func equalShares(names ...string) []model.Share {
+	shares := make([]model.Share, len(names))
+	for i, name := range names {
+		shares[i] = model.Share{MemberName: name, ShareWeight: 1}
+	}
+	return shares
+}
+
+// balancesOf updates the debts of a spreadsheet and returns the net balance of each member.
+func balancesOf(t *testing.T, manager *sheet.Manager) []string {
+	if err := manager.UpdateDebtors(); err != nil {
+		t.Fatal(err)
+	}
+	var balances []string
+	for _, balance := range ledger.ComputeBalances(manager.DebtMatrix()) {
+		balances = append(balances, balance.String())
+	}
+	return balances
+}
+
+// debtsOf updates the debts of a spreadsheet and returns its debt matrix.
+func debtsOf(t *testing.T, manager *sheet.Manager) [][]string {
+	if err := manager.UpdateDebtors(); err != nil {
+		t.Fatal(err)
+	}
+	var debts [][]string
+	for _, row := range manager.DebtMatrix() {
+		var debtRow []string
+		for _, debt := range row {
+			debtRow = append(debtRow, debt.String())
+		}
+		debts = append(debts, debtRow)
+	}
+	return debts
+}
+
+func TestManager_AddMember(t *testing.T) {
+	assert := assert2.New(t)
+
+	manager := newManager(t, "alice", "bob", "carol")
+	baseState := ledger.EmptyMatrix(3)
+	baseState[1][2] = model.AmountOf(5)
+	if err := manager.ReplaceRecords(manager.Rates(), nil, nil, baseState); err != nil {
+		t.Fatal(err)
+	}
+	err := manager.AddExpense(&model.Expense{Title: "dinner", PayerName: "alice", Amount: model.AmountOf(60),
+		Shares: equalShares("alice", "bob", "carol")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	manager = reloadSpreadsheet(t, manager)
+
+	assert.NoError(manager.AddMember(&model.Member{Name: "dave", CardNumber: "1234"}))
+	assert.Error(manager.AddMember(&model.Member{Name: "Dave"}))
+	fileName := saveSpreadsheet(t, manager)
+	file, err := excelize.OpenFile(fileName)
+	if !assert.NoError(err) {
+		return
+	}
+	// the share amount of alice in the dinner should be divided by the sum of the weights, including the one of dave
+	formula, err := file.GetCellFormula("expenses", "H4")
+	assert.NoError(err)
+	assert.Contains(formula, "IF(P4=TRUE, 1, P4)")
+
+	manager, err = sheet.LoadManager(fileName)
+	if !assert.NoError(err) {
+		return
+	}
+	dinner := manager.Ledger().Expenses[0]
+	assert.Equal("dave", dinner.Shares[3].MemberName)
+	assert.Zero(dinner.Shares[3].ShareWeight)
+	assert.Equal([][]string{
+		{"0", "0", "0", "0"},
+		{"20", "0", "5", "0"},
+		{"20", "0", "0", "0"},
+		{"0", "0", "0", "0"},
+	}, debtsOf(t, manager))
+
+	err = manager.AddExpense(&model.Expense{Title: "taxi", PayerName: "dave", Amount: model.AmountOf(40),
+		Shares: equalShares("alice", "dave")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	manager = reloadSpreadsheet(t, manager)
+	assert.Equal([][]string{
+		{"0", "0", "0", "20"},
+		{"20", "0", "5", "0"},
+		{"20", "0", "0", "0"},
+		{"0", "0", "0", "0"},
+	}, debtsOf(t, manager))
+}
+
+func TestManager_RemoveMember(t *testing.T) {
+	assert := assert2.New(t)
+
+	manager := newManager(t, "alice", "bob", "carol", "dave", "eve")
+	baseState := ledger.EmptyMatrix(5)
+	baseState[2][0] = model.AmountOf(4)
+	baseState[3][2] = model.AmountOf(3)
+	if err := manager.ReplaceRecords(manager.Rates(), nil, nil, baseState); err != nil {
+		t.Fatal(err)
+	}
+	for _, expense := range []*model.Expense{
+		{Title: "dinner", PayerName: "alice", Amount: model.AmountOf(60), Shares: equalShares("alice", "bob", "carol", "dave")},
+		{Title: "hotel", PayerName: "carol", Amount: model.AmountOf(90), Shares: equalShares("alice", "bob")},
+		{Title: "taxi", PayerName: "alice", Amount: model.AmountOf(10), Shares: equalShares("alice", "bob", "carol", "dave")},
+	} {
+		if err := manager.AddExpense(expense); err != nil {
+			t.Fatal(err)
+		}
+	}
+	err := manager.AddTransaction(&model.Transaction{ReceiverName: "carol", PayerName: "bob", Amount: model.AmountOf(30)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	balances := balancesOf(t, manager)
+	assert.Equal([]string{"-11", "33", "-42", "20", "0"}, balances)
+
+	assert.NoError(manager.RemoveMember("eve", ""))
+	assert.EqualError(manager.RemoveMember("carol", ""),
+		`member "carol" has a non-zero balance of -42; settle up first or transfer the balance to another member`)
+	assert.ErrorContains(manager.RemoveMember("carol", "bob"), `would become a payment of "bob" to themselves`)
+	if !assert.NoError(manager.RemoveMember("carol", "dave")) {
+		return
+	}
+
+	manager = reloadSpreadsheet(t, manager)
+	assert.Equal([]string{"-11", "33", "-22"}, balancesOf(t, manager))
+	l := manager.Ledger()
+	if assert.Len(l.Expenses, 3) && assert.Len(l.Transactions, 1) {
+		var weights []int
+		for _, share := range l.Expenses[0].Shares {
+			weights = append(weights, share.ShareWeight)
+		}
+		assert.Equal([]int{1, 1, 2}, weights)
+		assert.Equal("dave", l.Expenses[1].PayerName)
+		// splitting the taxi by 1:1:2 would round the share of bob down from 3 to 2
+		assert.Equal(model.SplitByExact, l.Expenses[2].SplitMode)
+		assert.Equal("dave", l.Transactions[0].ReceiverName)
+		assert.Equal("bob", l.Transactions[0].PayerName)
+	}
+	// the debt of carol to alice is now the debt of dave, and the debt of dave to carol is gone
+	assert.Equal("4", l.BaseState[2][0].String())
+	assert.Equal("0", l.BaseState[2][2].String())
+
+	err = manager.AddTransaction(&model.Transaction{ReceiverName: "dave", PayerName: "bob", Amount: model.AmountOf(22)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.EqualError(manager.RemoveMember("dave", ""),
+		`member "dave" still has 3 expenses, 2 transactions, debts in the base state; transfer them to another member or deactivate the member instead`)
+}
+
+func TestManager_DeactivateMember(t *testing.T) {
+	assert := assert2.New(t)
+
+	manager := newManager(t, "alice", "bob", "carol")
+	err := manager.AddExpense(&model.Expense{Title: "dinner", PayerName: "alice", Amount: model.AmountOf(60),
+		Shares: equalShares("alice", "bob", "carol")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	balances := balancesOf(t, manager)
+
+	assert.NoError(manager.DeactivateMember("bob"))
+	assert.EqualError(manager.DeactivateMember("Bob"), `member "bob" is already deactivated`)
+	fileName := saveSpreadsheet(t, manager)
+
+	manager, err = sheet.LoadManager(fileName)
+	if !assert.NoError(err) {
+		return
+	}
+	assert.True(manager.Ledger().Members[1].Deactivated)
+	assert.Equal(balances, balancesOf(t, manager))
+
+	file, err := excelize.OpenFile(fileName)
+	if !assert.NoError(err) {
+		return
+	}
+	// the share weight of bob in the dummy row
+	weight, err := file.GetCellValue("expenses", "J3")
+	assert.NoError(err)
+	assert.Equal("0", weight)
+	visible, err := file.GetColVisible("expenses", "J")
+	assert.NoError(err)
+	assert.False(visible)
+}
+
+func TestManager_RenameMember(t *testing.T) {
+	assert := assert2.New(t)
+
+	manager := newManager(t, "alice", "bob", "carol")
+	err := manager.AddExpense(&model.Expense{Title: "dinner", PayerName: "bob", Amount: model.AmountOf(60),
+		Shares: equalShares("alice", "bob", "carol")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = manager.AddTransaction(&model.Transaction{ReceiverName: "bob", PayerName: "carol", Amount: model.AmountOf(20)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	debts := debtsOf(t, manager)
+	fileName := saveSpreadsheet(t, manager)
+	editSpreadsheet(t, fileName, func(file *excelize.File) error {
+		err := file.SetCellValue("settlement constraints", "B2", "bob")
+		if err != nil {
+			return err
+		}
+		return file.SetSheetRow("settlement constraints", "A7", &[]any{"bob", "carol"})
+	})
+	manager, err = sheet.LoadManager(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.EqualError(manager.RenameMember("alice", " Carol"), `duplicate member name " Carol"`)
+	assert.NoError(manager.RenameMember("BOB", "robert"))
+	manager = reloadSpreadsheet(t, manager)
+
+	l := manager.Ledger()
+	assert.Equal("robert", l.Members[1].Name)
+	assert.Equal("robert", l.Expenses[0].PayerName)
+	assert.Equal("robert", l.Expenses[0].Shares[1].MemberName)
+	assert.Equal("robert", l.Transactions[1].ReceiverName)
+	assert.Equal(&ledger.SettlementConstraints{
+		Treasurer:     "robert",
+		Blocked:       []ledger.BlockedPair{{PayerName: "robert", ReceiverName: "carol"}},
+		MinimumAmount: model.AmountZero(),
+	}, manager.SettlementConstraints())
+	assert.Equal(debts, debtsOf(t, manager))
+	if assert.Len(manager.Settlements(), 1) {
+		assert.Equal("robert", manager.Settlements()[0].ReceiverName)
+	}
+}
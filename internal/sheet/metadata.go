@@ -0,0 +1,159 @@
+package sheet
+
+import (
+	"fmt"
+	"github.com/MeysamBavi/group-expense-manager/internal/log"
+	"github.com/MeysamBavi/group-expense-manager/internal/model"
+	"github.com/MeysamBavi/group-expense-manager/internal/sheet/style"
+	"github.com/MeysamBavi/group-expense-manager/internal/sheet/table"
+	"github.com/MeysamBavi/group-expense-manager/pkg/ledger"
+	"strconv"
+	"strings"
+)
+
+const (
+	MaxFractionDigits = 8
+)
+
+const (
+	fractionDigitsKey = "fraction digits"
+	roundingKey       = "rounding"
+	baseCurrencyKey   = "base currency"
+	settlementKey     = "settlement"
+	periodStartKey    = "period start"
+	periodEndKey      = "period end"
+	previousPeriodKey = "previous period"
+	nextPeriodKey     = "next period"
+)
+
+// Settings holds the options of a spreadsheet which are stored in the metadata sheet.
+type Settings struct {
+	// FractionDigits is the number of digits after the decimal point used for amounts.
+	FractionDigits int
+	// Rounding specifies how the amount of an expense is split into shares.
+	Rounding model.RoundingPolicy
+	// BaseCurrency is the currency that the debts are calculated in. It can be empty.
+	BaseCurrency string
+	// Settlement specifies how the settlements are chosen.
+	Settlement ledger.SettlementMode
+	// PeriodStart is the time that the accounting period of the file started, when the previous period was closed. It
+	// is nil for the first period.
+	PeriodStart model.Time
+	// PeriodEnd is the time that the period was closed. It is nil while the period is open.
+	PeriodEnd model.Time
+	// PreviousPeriod and NextPeriod are the names of the files of the previous and the next periods, if there are any.
+	PreviousPeriod string
+	NextPeriod     string
+}
+
+func DefaultSettings() *Settings {
+	return &Settings{
+		FractionDigits: 0,
+		Rounding:       model.LargestRemainder,
+		Settlement:     ledger.GreedySettlement,
+	}
+}
+
+func (s *Settings) Validate() error {
+	if s.FractionDigits < 0 || s.FractionDigits > MaxFractionDigits {
+		return fmt.Errorf("fraction digits should be between 0 and %d", MaxFractionDigits)
+	}
+	return nil
+}
+
+// metadataEntries returns the key-value pairs written in the metadata sheet after the theme code.
+func (s *Settings) metadataEntries() [][2]string {
+	return [][2]string{
+		{fractionDigitsKey, strconv.Itoa(s.FractionDigits)},
+		{roundingKey, string(s.Rounding)},
+		{baseCurrencyKey, s.BaseCurrency},
+		{settlementKey, string(s.Settlement)},
+		{periodStartKey, timeString(s.PeriodStart)},
+		{periodEndKey, timeString(s.PeriodEnd)},
+		{previousPeriodKey, s.PreviousPeriod},
+		{nextPeriodKey, s.NextPeriod},
+	}
+}
+
+func (s *Settings) setMetadataEntry(key, value string) error {
+	var err error
+	switch key {
+	case fractionDigitsKey:
+		s.FractionDigits, err = strconv.Atoi(value)
+	case roundingKey:
+		s.Rounding, err = model.ParseRoundingPolicy(value)
+	case baseCurrencyKey:
+		s.BaseCurrency = strings.ToUpper(value)
+	case settlementKey:
+		s.Settlement, err = ledger.ParseSettlementMode(value)
+	case periodStartKey:
+		s.PeriodStart, err = ParsePeriodTime(value)
+	case periodEndKey:
+		s.PeriodEnd, err = ParsePeriodTime(value)
+	case previousPeriodKey:
+		s.PreviousPeriod = value
+	case nextPeriodKey:
+		s.NextPeriod = value
+	default:
+		return fmt.Errorf("unknown metadata key %q", key)
+	}
+	return err
+}
+
+// ParsePeriodTime parses the start or the end of a period. An empty value means no time.
+func ParsePeriodTime(value string) (model.Time, error) {
+	if strings.TrimSpace(value) == "" {
+		return nil, nil
+	}
+	return model.ParseTime(value)
+}
+
+func timeString(t model.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.String()
+}
+
+func initializeMetadata(m *Manager) error {
+	return m.writeMetadata()
+}
+
+// writeMetadata writes the theme code in the first row and the settings as key-value pairs in the next rows.
+func (m *Manager) writeMetadata() error {
+	entries := m.settings.metadataEntries()
+	return m.metadataTable.WriteRows(table.WriteRowsParams{
+		RowCount: len(entries) + 1,
+		RowWriter: func(rowNumber int, cells []*table.WCell) {
+			if rowNumber == 0 {
+				cells[0].Value = m.theme.Code()
+				return
+			}
+			cells[0].Value = entries[rowNumber-1][0]
+			cells[1].Value = entries[rowNumber-1][1]
+		},
+	})
+}
+
+// loadMetadata reads the theme and the settings. Settings missing from older spreadsheets get their default value.
+func loadMetadata(t *table.Table) (*style.Theme, *Settings, error) {
+	var errs log.ErrorList
+	var theme *style.Theme
+	settings := DefaultSettings()
+	err := t.ReadRows(table.ReadRowsParams{
+		RowReader: func(rowNumber int, cells []*table.RCell) {
+			if rowNumber == 0 {
+				theme = style.ThemeFromCode(cells[0].Value)
+				return
+			}
+			err := settings.setMetadataEntry(strings.TrimSpace(cells[0].Value), strings.TrimSpace(cells[1].Value))
+			errs.Add(log.CellErrorOf(err, t.SheetName, t.GetCell(rowNumber, 1)))
+		},
+		IncludeHeader:   false,
+		UnknownRowCount: true,
+	})
+	errs.Add(err)
+	errs.Add(log.SheetErrorOf(settings.Validate(), t.SheetName))
+
+	return theme, settings, errs.Err()
+}
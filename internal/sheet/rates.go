@@ -0,0 +1,100 @@
+package sheet
+
+import (
+	"github.com/MeysamBavi/group-expense-manager/internal/log"
+	"github.com/MeysamBavi/group-expense-manager/internal/model"
+	"github.com/MeysamBavi/group-expense-manager/internal/sheet/store"
+	"github.com/MeysamBavi/group-expense-manager/internal/sheet/style"
+	"github.com/MeysamBavi/group-expense-manager/internal/sheet/table"
+	"strings"
+	"time"
+)
+
+// exampleCurrency is the currency of the dummy row of the rates sheet. It is the ISO 4217 code for "no currency".
+const exampleCurrency = "XXX"
+
+func initializeRates(m *Manager) error {
+	m.rates = store.NewRateStore(m.settings.BaseCurrency)
+	exampleTime := model.TimeOfGregorian(time.Date(2007, time.May, 13, 0, 0, 0, 0, time.Local))
+	err := m.rates.AddRate(exampleCurrency, exampleTime, model.AmountOf(1))
+	if err != nil {
+		return err
+	}
+
+	return m.writeRates()
+}
+
+func (m *Manager) writeRates() error {
+	type rateRow struct {
+		time     model.Time
+		currency string
+		value    model.Amount
+	}
+	var rows []rateRow
+	m.rates.Range(func(currency string, t model.Time, value model.Amount) {
+		rows = append(rows, rateRow{time: t, currency: currency, value: value})
+	})
+
+	return m.ratesTable.WriteRows(table.WriteRowsParams{
+		RowCount: len(rows) + 1,
+		HeaderWriter: func(cells []*table.WCell, mergeCount *int) {
+			*mergeCount = m.ratesTable.ColumnCount
+			cells[0].Value = "Value of one unit of each currency in the base currency. Rates without a date apply to all dates."
+			cells[0].Style = newInt(m.getStyle(helpStyle))
+		},
+		RowWriter: func(rowNumber int, cells []*table.WCell) {
+			if rowNumber == 0 {
+				cells[0].Value = "Date"
+				cells[1].Value = "Currency"
+				cells[2].Value = "Rate"
+				return
+			}
+			row := rows[rowNumber-1]
+			cells[0].Value = row.time.String()
+			cells[1].Value = row.currency
+			cells[2].Value = row.value.ToFloat(MaxFractionDigits)
+		},
+		ColumnWidth: 18,
+		RowStyler: func(row int) (int, bool) {
+			if row == 0 {
+				return m.getStyle(headerBoxStyle), true
+			}
+			return 0, false
+		},
+		ConditionalStyles: style.Alternate(m.getStyle(alternate0Style), m.getStyle(alternate1Style), m.getStyle(alternate2Style)).
+			WithStart(1, 0).
+			WithEnd(len(rows), m.ratesTable.ColumnCount-1).
+			Build(),
+		ClearBeforeWrite: true,
+	})
+}
+
+// loadRates reads the exchange rates. It does not stop at the first invalid cell; all the errors are returned together.
+func loadRates(t *table.Table, baseCurrency string) (*store.RateStore, error) {
+	var errs log.ErrorList
+	rates := store.NewRateStore(baseCurrency)
+	err := t.ReadRows(table.ReadRowsParams{
+		RowReader: func(rowNumber int, cells []*table.RCell) {
+			if rowNumber == 0 {
+				return
+			}
+
+			theTime, timeErr := model.ParseTime(cells[0].Value)
+			errs.Add(log.CellErrorOf(timeErr, t.SheetName, t.GetCell(rowNumber, 0)))
+
+			rate, rateErr := model.ParseAmount(cells[2].Value)
+			errs.Add(log.CellErrorOf(rateErr, t.SheetName, t.GetCell(rowNumber, 2)))
+
+			if timeErr == nil && rateErr == nil {
+				err := rates.AddRate(strings.TrimSpace(cells[1].Value), theTime, rate)
+				errs.Add(log.CellErrorOf(err, t.SheetName, t.GetCell(rowNumber, 1)))
+			}
+		},
+		IncludeHeader:   false,
+		UnknownRowCount: true,
+	})
+
+	errs.Add(err)
+
+	return rates, errs.Err()
+}
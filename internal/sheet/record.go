@@ -0,0 +1,67 @@
+package sheet
+
+import (
+	"github.com/MeysamBavi/group-expense-manager/internal/model"
+	"github.com/MeysamBavi/group-expense-manager/internal/sheet/store"
+	"github.com/MeysamBavi/group-expense-manager/internal/sheet/style"
+	"github.com/MeysamBavi/group-expense-manager/internal/sheet/table"
+)
+
+// AddExpense validates an expense and appends it to the expenses sheet, at the first empty row.
+// If the expenses sheet has been created by an older version, it is rewritten using the latest layout.
+func (m *Manager) AddExpense(expense *model.Expense) error {
+	err := store.PrepareExpense(expense, m.members, m.rates)
+	if err != nil {
+		return err
+	}
+
+	m.expenses = append(m.expenses, expense)
+	if !m.expensesLayout.isLatest() {
+		return m.writeExpenses()
+	}
+
+	rowNumber := len(m.expenses) - 1
+	err = m.expensesLeftTable.WriteRows(table.WriteRowsParams{
+		StartRow:  rowNumber,
+		RowCount:  rowNumber + 1,
+		RowWriter: m.writeExpenseCommonCells,
+		ConditionalStyles: style.Alternate(m.getStyle(alternate0Style), m.getStyle(alternate1Style), m.getStyle(alternate2Style)).
+			WithStart(rowNumber, 0).
+			WithEnd(rowNumber, m.expensesLeftTable.ColumnCount+m.expensesRightTable.ColumnCount-1).
+			Build(),
+	})
+	if err != nil {
+		return err
+	}
+
+	return m.expensesRightTable.WriteRows(table.WriteRowsParams{
+		StartRow: rowNumber + 1,
+		RowCount: rowNumber + 2,
+		RowWriter: func(rowNumber int, cells []*table.WCell) {
+			for i := 0; i < m.MembersCount(); i++ {
+				m.writeExpenseMemberCells(rowNumber, i, cells[i*len(m.expensesLayout.memberHeaders):])
+			}
+		},
+	})
+}
+
+// AddTransaction validates a transaction and appends it to the transactions sheet, at the first empty row.
+func (m *Manager) AddTransaction(transaction *model.Transaction) error {
+	err := store.PrepareTransaction(transaction, m.members, m.rates)
+	if err != nil {
+		return err
+	}
+
+	m.transactions = append(m.transactions, transaction)
+	rowNumber := len(m.transactions) - 1
+	return m.transactionsTable.WriteRows(table.WriteRowsParams{
+		StartRow:  rowNumber,
+		RowCount:  rowNumber + 1,
+		RowWriter: m.writeTransactionCells,
+		ConditionalStyles: style.Alternate(m.getStyle(alternate0Style), m.getStyle(alternate1Style), m.getStyle(alternate2Style)).
+			WithStart(rowNumber, 0).
+			WithEnd(rowNumber, m.transactionsTable.ColumnCount-1).
+			WithModOffset(2).
+			Build(),
+	})
+}
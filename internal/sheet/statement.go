@@ -0,0 +1,97 @@
+package sheet
+
+import (
+	"fmt"
+	"github.com/MeysamBavi/group-expense-manager/internal/log"
+	"github.com/MeysamBavi/group-expense-manager/internal/sheet/style"
+	"github.com/MeysamBavi/group-expense-manager/internal/sheet/table"
+	"github.com/MeysamBavi/group-expense-manager/pkg/ledger"
+	"strings"
+)
+
+const (
+	statementSheetPrefix = "statement "
+	// maxSheetNameLength is the maximum length of a sheet name allowed by Excel.
+	maxSheetNameLength = 31
+
+	statementRowOffset = 2
+	statementColOffset = 1
+)
+
+// WriteStatement writes the statement of a member in a sheet named after the member. The sheet is created if it does
+// not exist; otherwise it is rewritten.
+func (m *Manager) WriteStatement(memberName string, entries []ledger.StatementEntry) error {
+	sheetName := statementSheetName(memberName)
+	if !m.hasSheet(sheetName) {
+		_, err := m.file.NewSheet(sheetName)
+		if err != nil {
+			return log.SheetErrorOf(err, sheetName)
+		}
+	}
+
+	statementTable := &table.Table{
+		File:         m.file,
+		SheetName:    sheetName,
+		RowOffset:    statementRowOffset,
+		ColumnOffset: statementColOffset,
+		ColumnCount:  5,
+	}
+	return statementTable.WriteRows(table.WriteRowsParams{
+		RowCount: len(entries) + 1,
+		HeaderWriter: func(cells []*table.WCell, mergeCount *int) {
+			*mergeCount = statementTable.ColumnCount
+			cells[0].Value = fmt.Sprintf("Run 'statement' command to update the statement of %s. "+
+				"Credit is what the others owe them and debit is what they owe the others.", memberName)
+			cells[0].Style = newInt(m.getStyle(helpStyle))
+		},
+		RowWriter: func(rowNumber int, cells []*table.WCell) {
+			if rowNumber == 0 {
+				cells[0].Value = "Time"
+				cells[1].Value = "Description"
+				cells[2].Value = "Credit"
+				cells[3].Value = "Debit"
+				cells[4].Value = "Balance"
+				return
+			}
+			entry := entries[rowNumber-1]
+			if entry.Time != nil {
+				cells[0].Value = entry.Time.String()
+			}
+			cells[1].Value = entry.Description
+			for i, amount := range []ledger.Amount{entry.Credit, entry.Debit, entry.Balance} {
+				cells[i+2].Value = m.amountValue(amount)
+				if amount.IsZero() && i < 2 {
+					cells[i+2].Value = ""
+				}
+				cells[i+2].Style = newInt(m.getStyle(moneyStyle))
+			}
+		},
+		ColumnWidth: 20,
+		RowStyler: func(row int) (int, bool) {
+			if row == 0 {
+				return m.getStyle(headerBoxStyle), true
+			}
+			return 0, false
+		},
+		ConditionalStyles: style.Alternate(m.getStyle(alternate0Style), m.getStyle(alternate1Style), m.getStyle(alternate2Style)).
+			WithStart(1, 0).
+			WithEnd(len(entries), statementTable.ColumnCount-1).
+			Build(),
+		ClearBeforeWrite: true,
+	})
+}
+
+// statementSheetName returns the name of the statement sheet of a member. The characters that are not allowed in sheet
+// names are replaced and the name is cut to the maximum length.
+func statementSheetName(memberName string) string {
+	name := []rune(statementSheetPrefix + strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return '_'
+		}
+		return r
+	}, memberName))
+	if len(name) > maxSheetNameLength {
+		name = name[:maxSheetNameLength]
+	}
+	return string(name)
+}
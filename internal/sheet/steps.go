@@ -0,0 +1,95 @@
+package sheet
+
+import (
+	"github.com/MeysamBavi/group-expense-manager/internal/log"
+	"github.com/MeysamBavi/group-expense-manager/internal/model"
+	"github.com/MeysamBavi/group-expense-manager/internal/sheet/style"
+	"github.com/MeysamBavi/group-expense-manager/internal/sheet/table"
+	"github.com/MeysamBavi/group-expense-manager/pkg/ledger"
+)
+
+const (
+	settlementStepsSheet = "settlement steps"
+
+	settlementStepsRowOffset = 2
+	settlementStepsColOffset = 1
+)
+
+// WriteSettlementSteps writes the settlement steps sheet, which shows the net balance of each member before settling
+// and after each settlement. The sheet is created if it does not exist; after that, it is rewritten whenever the
+// settlements are set.
+func (m *Manager) WriteSettlementSteps() error {
+	if !m.hasSheet(settlementStepsSheet) {
+		_, err := m.file.NewSheet(settlementStepsSheet)
+		if err != nil {
+			return log.SheetErrorOf(err, settlementStepsSheet)
+		}
+	}
+	return m.writeSettlementSteps()
+}
+
+func (m *Manager) writeSettlementSteps() error {
+	before, steps, err := ledger.ExplainSettlements(m.Ledger().Members, m.debtMatrix, m.settlements)
+	if err != nil {
+		return log.SheetErrorOf(err, settlementStepsSheet)
+	}
+
+	stepsTable := &table.Table{
+		File:         m.file,
+		SheetName:    settlementStepsSheet,
+		RowOffset:    settlementStepsRowOffset,
+		ColumnOffset: settlementStepsColOffset,
+		ColumnCount:  4 + m.MembersCount(),
+	}
+	writeBalances := func(balances []model.Amount, cells []*table.WCell) {
+		for i, balance := range balances {
+			// the balances of the ledger are positive for debtors
+			cells[i].Value = m.amountValue(balance.Negative())
+			cells[i].Style = newInt(m.getStyle(moneyStyle))
+		}
+	}
+	return stepsTable.WriteRows(table.WriteRowsParams{
+		RowCount: len(steps) + 2,
+		HeaderWriter: func(cells []*table.WCell, mergeCount *int) {
+			*mergeCount = stepsTable.ColumnCount
+			cells[0].Value = "Run 'update' command to update the steps. Each row shows the net balance of every member after " +
+				"the settlement; positive means the member gets money back and negative means they owe money."
+			cells[0].Style = newInt(m.getStyle(helpStyle))
+		},
+		RowWriter: func(rowNumber int, cells []*table.WCell) {
+			switch rowNumber {
+			case 0:
+				cells[0].Value = "Step"
+				cells[1].Value = "Payer"
+				cells[2].Value = "Receiver"
+				cells[3].Value = "Amount"
+				m.members.Range(func(i int, member *model.Member) {
+					cells[i+4].Value = member.Name
+				})
+			case 1:
+				cells[0].Value = "before settling"
+				writeBalances(before, cells[4:])
+			default:
+				step := steps[rowNumber-2]
+				cells[0].Value = rowNumber - 1
+				cells[1].Value = step.Settlement.PayerName
+				cells[2].Value = step.Settlement.ReceiverName
+				cells[3].Value = m.amountValue(step.Settlement.Amount)
+				cells[3].Style = newInt(m.getStyle(moneyStyle))
+				writeBalances(step.Balances, cells[4:])
+			}
+		},
+		ColumnWidth: 18,
+		RowStyler: func(row int) (int, bool) {
+			if row == 0 {
+				return m.getStyle(headerBoxStyle), true
+			}
+			return 0, false
+		},
+		ConditionalStyles: style.Alternate(m.getStyle(alternate0Style), m.getStyle(alternate1Style), m.getStyle(alternate2Style)).
+			WithStart(1, 0).
+			WithEnd(len(steps)+1, stepsTable.ColumnCount-1).
+			Build(),
+		ClearBeforeWrite: true,
+	})
+}
@@ -38,6 +38,42 @@ func (ms *MemberStore) AddMember(member *model.Member) error {
 	return nil
 }
 
+func (ms *MemberStore) RemoveMember(name string) error {
+	index := ms.GetIndexByName(name)
+	if index == -1 {
+		return fmt.Errorf("found no member with name %q", name)
+	}
+
+	ms.memberByIndex = append(ms.memberByIndex[:index], ms.memberByIndex[index+1:]...)
+	ms.indexByName = make(map[string]int)
+	for i, member := range ms.memberByIndex {
+		ms.indexByName[standardizeName(member.Name)] = i
+	}
+	return nil
+}
+
+func (ms *MemberStore) RenameMember(oldName, newName string) error {
+	index := ms.GetIndexByName(oldName)
+	if index == -1 {
+		return fmt.Errorf("found no member with name %q", oldName)
+	}
+
+	name := standardizeName(newName)
+	if name == "" {
+		return fmt.Errorf("empty or whitespace name")
+	}
+
+	i, found := ms.indexByName[name]
+	if found && i != index {
+		return fmt.Errorf("duplicate member name %q", newName)
+	}
+
+	delete(ms.indexByName, standardizeName(oldName))
+	ms.indexByName[name] = index
+	ms.memberByIndex[index].Name = newName
+	return nil
+}
+
 func (ms *MemberStore) GetMemberByName(name string) (*model.Member, bool) {
 	name = standardizeName(name)
 	i, ok := ms.indexByName[name]
@@ -0,0 +1,51 @@
+package store_test
+
+import (
+	"github.com/MeysamBavi/group-expense-manager/internal/model"
+	"github.com/MeysamBavi/group-expense-manager/internal/sheet/store"
+	assert2 "github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestMemberStore_RemoveMember(t *testing.T) {
+	assert := assert2.New(t)
+
+	members := store.NewMemberStore()
+	for _, name := range []string{"alice", "bob", "carol"} {
+		assert.NoError(members.AddMember(&model.Member{Name: name}))
+	}
+
+	assert.Error(members.RemoveMember("dave"))
+	assert.NoError(members.RemoveMember(" Bob "))
+
+	assert.Equal(2, members.Count())
+	assert.False(members.IsPresent("bob"))
+	assert.Equal(0, members.GetIndexByName("alice"))
+	assert.Equal(1, members.GetIndexByName("carol"))
+	assert.True(members.IsValid("carol", 1))
+
+	assert.NoError(members.AddMember(&model.Member{Name: "bob"}))
+	assert.Equal(2, members.GetIndexByName("bob"))
+}
+
+func TestMemberStore_RenameMember(t *testing.T) {
+	assert := assert2.New(t)
+
+	members := store.NewMemberStore()
+	for _, name := range []string{"alice", "bob"} {
+		assert.NoError(members.AddMember(&model.Member{Name: name}))
+	}
+
+	assert.Error(members.RenameMember("carol", "dave"))
+	assert.Error(members.RenameMember("alice", " BOB"))
+	assert.Error(members.RenameMember("alice", "  "))
+
+	assert.NoError(members.RenameMember("alice", "Alice"))
+	assert.True(members.IsValid("alice", 0))
+	assert.Equal("Alice", members.RequireMemberByIndex(0).Name)
+
+	assert.NoError(members.RenameMember("ALICE", "carol"))
+	assert.False(members.IsPresent("alice"))
+	assert.True(members.IsValid("carol", 0))
+	assert.True(members.IsValid("bob", 1))
+}
@@ -0,0 +1,102 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"github.com/MeysamBavi/group-expense-manager/internal/model"
+	"strings"
+	"time"
+)
+
+type rate struct {
+	// time is zero if the rate applies to all dates.
+	time  time.Time
+	value model.Amount
+}
+
+// RateStore holds the exchange rates of currencies to the base currency of the group.
+type RateStore struct {
+	baseCurrency string
+	rates        map[string][]rate
+	// currencies holds the keys of rates in the order they were added.
+	currencies []string
+}
+
+func NewRateStore(baseCurrency string) *RateStore {
+	return &RateStore{
+		baseCurrency: standardizeCurrency(baseCurrency),
+		rates:        make(map[string][]rate),
+	}
+}
+
+// AddRate adds the value of one unit of the currency in the base currency, from the given time on.
+// If the time is not specified, the rate applies to all dates that have no other rate.
+func (rs *RateStore) AddRate(currency string, t model.Time, value model.Amount) error {
+	currency = standardizeCurrency(currency)
+	if currency == "" {
+		return errors.New("empty or whitespace currency")
+	}
+	if !value.IsPositive() {
+		return fmt.Errorf("exchange rate of %q should be positive", currency)
+	}
+
+	if _, ok := rs.rates[currency]; !ok {
+		rs.currencies = append(rs.currencies, currency)
+	}
+	rs.rates[currency] = append(rs.rates[currency], rate{
+		time:  t.ToGregorian(),
+		value: value,
+	})
+	return nil
+}
+
+// Range calls the consumer for every rate, grouped by currency in the order they were added.
+// The time of a rate without a date is unspecified.
+func (rs *RateStore) Range(consumer func(currency string, t model.Time, value model.Amount)) {
+	for _, currency := range rs.currencies {
+		for _, r := range rs.rates[currency] {
+			consumer(currency, model.TimeOfGregorian(r.time), r.value)
+		}
+	}
+}
+
+func (rs *RateStore) IsBaseCurrency(currency string) bool {
+	currency = standardizeCurrency(currency)
+	return currency == "" || currency == rs.baseCurrency
+}
+
+// Convert converts an amount in the given currency at the given time to the base currency.
+// The latest rate on or before the time is used; if there is none, the rate without a date is used.
+func (rs *RateStore) Convert(amount model.Amount, currency string, t model.Time) (model.Amount, error) {
+	if rs.IsBaseCurrency(currency) {
+		return amount, nil
+	}
+
+	theTime := t.ToGregorian()
+	rates := rs.rates[standardizeCurrency(currency)]
+	var found *rate
+	for i, r := range rates {
+		switch {
+		case r.time.IsZero():
+			if found == nil {
+				found = &rates[i]
+			}
+		case theTime.IsZero() || r.time.After(theTime):
+			continue
+		case found == nil || found.time.IsZero() || found.time.Before(r.time):
+			found = &rates[i]
+		}
+	}
+
+	if found == nil {
+		if theTime.IsZero() {
+			return model.AmountZero(), fmt.Errorf("found no exchange rate for %q", currency)
+		}
+		return model.AmountZero(), fmt.Errorf("found no exchange rate for %q on %s", currency, t)
+	}
+	return amount.MultiplyBy(found.value), nil
+}
+
+func standardizeCurrency(currency string) string {
+	return strings.ToUpper(strings.TrimSpace(currency))
+}
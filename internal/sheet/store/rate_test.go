@@ -0,0 +1,65 @@
+package store_test
+
+import (
+	"github.com/MeysamBavi/group-expense-manager/internal/model"
+	"github.com/MeysamBavi/group-expense-manager/internal/sheet/store"
+	assert2 "github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func mustParseTime(t *testing.T, value string) model.Time {
+	result, err := model.ParseTime(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return result
+}
+
+func mustParseAmount(t *testing.T, value string) model.Amount {
+	result, err := model.ParseAmount(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return result
+}
+
+func TestRateStore_Convert(t *testing.T) {
+	assert := assert2.New(t)
+
+	rates := store.NewRateStore("usd")
+	assert.NoError(rates.AddRate("EUR", mustParseTime(t, "2023/1/1"), mustParseAmount(t, "1.1")))
+	assert.NoError(rates.AddRate("eur", mustParseTime(t, "2023/2/1"), mustParseAmount(t, "1.2")))
+	assert.NoError(rates.AddRate(" gbp ", mustParseTime(t, ""), mustParseAmount(t, "1.5")))
+	assert.NoError(rates.AddRate("GBP", mustParseTime(t, "2023/3/1"), mustParseAmount(t, "1.25")))
+	assert.Error(rates.AddRate("", mustParseTime(t, ""), mustParseAmount(t, "1")))
+	assert.Error(rates.AddRate("JPY", mustParseTime(t, ""), mustParseAmount(t, "0")))
+
+	convert := func(amount, currency, time string) (string, error) {
+		result, err := rates.Convert(mustParseAmount(t, amount), currency, mustParseTime(t, time))
+		return result.String(), err
+	}
+
+	for _, c := range []struct{ amount, currency, time, expected string }{
+		{"100", "", "2023/1/1", "100"},
+		{"100", "USD", "", "100"},
+		{"100", "eur", "2023/1/1", "110"},
+		{"100", "EUR", "2023/1/31 23:59", "110"},
+		{"100", "EUR", "2023/2/1 12:00", "120"},
+		{"100", "gbp", "2023/2/1", "150"},
+		{"100", "gbp", "", "150"},
+		{"100", "gbp", "2023/3/2", "125"},
+	} {
+		result, err := convert(c.amount, c.currency, c.time)
+		assert.NoError(err, c)
+		assert.Equal(c.expected, result, c)
+	}
+
+	for _, c := range []struct{ currency, time string }{
+		{"EUR", "2022/12/31"},
+		{"EUR", ""},
+		{"JPY", "2023/1/1"},
+	} {
+		_, err := convert("100", c.currency, c.time)
+		assert.Error(err, c)
+	}
+}
@@ -0,0 +1,90 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"github.com/MeysamBavi/group-expense-manager/internal/model"
+	"time"
+)
+
+// PrepareExpense validates a new expense against the members and the exchange rates.
+// The shares can name any subset of the members in any order; they are put in the order of the members and the
+// members that are not named get an empty share. The names of the members are replaced with their stored form.
+// A nil time is replaced with an unspecified time.
+func PrepareExpense(expense *model.Expense, members *MemberStore, rates *RateStore) error {
+	if expense.Time == nil {
+		expense.Time = model.TimeOfGregorian(time.Time{})
+	}
+	shares, err := orderShares(expense.Shares, members)
+	if err != nil {
+		return err
+	}
+	expense.Shares = shares
+
+	if expense.SplitMode == "" {
+		expense.SplitMode = model.SplitByWeight
+	}
+	if expense.PayerName != "" || !expense.HasPaidPortions() {
+		payer, ok := members.GetMemberByName(expense.PayerName)
+		if !ok {
+			return fmt.Errorf("found no member with name %q", expense.PayerName)
+		}
+		expense.PayerName = payer.Name
+	}
+	if payerIndex := members.GetIndexByName(expense.PayerName); expense.HasPaidPortions() && payerIndex != -1 &&
+		expense.Shares[payerIndex].Paid.IsZero() {
+		return fmt.Errorf("payer %q has not paid any portion of the amount", expense.PayerName)
+	}
+	if err := expense.ValidateShares(); err != nil {
+		return err
+	}
+	if err := expense.ValidatePaidPortions(); err != nil {
+		return err
+	}
+	_, err = rates.Convert(expense.Amount, expense.Currency, expense.Time)
+	return err
+}
+
+// PrepareTransaction validates a new transaction against the members and the exchange rates.
+// The names of the receiver and the payer are replaced with their stored form. A nil time is replaced with an
+// unspecified time.
+func PrepareTransaction(transaction *model.Transaction, members *MemberStore, rates *RateStore) error {
+	if transaction.Time == nil {
+		transaction.Time = model.TimeOfGregorian(time.Time{})
+	}
+	receiver, ok := members.GetMemberByName(transaction.ReceiverName)
+	if !ok {
+		return fmt.Errorf("found no member with name %q", transaction.ReceiverName)
+	}
+	payer, ok := members.GetMemberByName(transaction.PayerName)
+	if !ok {
+		return fmt.Errorf("found no member with name %q", transaction.PayerName)
+	}
+	if receiver == payer {
+		return errors.New("receiver and payer of a transaction should be different")
+	}
+	transaction.ReceiverName, transaction.PayerName = receiver.Name, payer.Name
+	_, err := rates.Convert(transaction.Amount, transaction.Currency, transaction.Time)
+	return err
+}
+
+// orderShares returns the shares of all the members in the order of members.
+func orderShares(shares []model.Share, members *MemberStore) ([]model.Share, error) {
+	result := make([]model.Share, members.Count())
+	named := make([]bool, members.Count())
+	for _, share := range shares {
+		i := members.GetIndexByName(share.MemberName)
+		if i == -1 {
+			return nil, fmt.Errorf("found no member with name %q", share.MemberName)
+		}
+		if named[i] {
+			return nil, fmt.Errorf("member %q is named more than once", share.MemberName)
+		}
+		named[i] = true
+		result[i] = share
+	}
+	members.Range(func(i int, member *model.Member) {
+		result[i].MemberName = member.Name
+	})
+	return result, nil
+}
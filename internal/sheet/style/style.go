@@ -1,6 +1,9 @@
 package style
 
-import "github.com/xuri/excelize/v2"
+import (
+	"github.com/xuri/excelize/v2"
+	"strings"
+)
 
 type Builder struct {
 	style *excelize.Style
@@ -73,7 +76,16 @@ func (b *Builder) WithCenterAlignment() *Builder {
 	return b
 }
 
-func (b *Builder) WithMoneyFormat() *Builder {
-	b.style.NumFmt = 3
+// WithMoneyFormat sets a number format with thousands separators and the given number of fraction digits.
+func (b *Builder) WithMoneyFormat(fractionDigits int) *Builder {
+	switch fractionDigits {
+	case 0:
+		b.style.NumFmt = 3
+	case 2:
+		b.style.NumFmt = 4
+	default:
+		format := "#,##0." + strings.Repeat("0", fractionDigits)
+		b.style.CustomNumFmt = &format
+	}
 	return b
 }
@@ -27,7 +27,7 @@ const (
 func createStyles(m *Manager) {
 	builder := style.Empty()
 
-	m.setStyle(moneyStyle, builder.WithMoneyFormat().Build())
+	m.setStyle(moneyStyle, builder.WithMoneyFormat(m.settings.FractionDigits).Build())
 	m.setStyle(secondHeaderBoxStyle, builder.WithBackground(m.theme.SecondHeaderBGColor).
 		WithCenterAlignment().WithFullBoarders(m.theme.BorderColor).
 		WithFont(9, false, defaultFontColor).Build())
@@ -15,19 +15,7 @@ type Table struct {
 	ColumnCount             int
 }
 
-func (t *Table) fatalIfNotNil(err error) {
-	if err != nil {
-		log.FatalErrorByCaller(log.SheetErrorOf(err, t.SheetName))
-	}
-}
-
-func (t *Table) fatalIfNotNilWithCell(err error, cell string) {
-	if err != nil {
-		log.FatalErrorByCaller(log.CellErrorOf(err, t.SheetName, cell))
-	}
-}
-
-func (t *Table) WriteRows(params WriteRowsParams) {
+func (t *Table) WriteRows(params WriteRowsParams) error {
 	cells := make([]*WCell, t.ColumnCount)
 	for i := range cells {
 		cells[i] = &WCell{}
@@ -35,13 +23,21 @@ func (t *Table) WriteRows(params WriteRowsParams) {
 
 	if params.ClearBeforeWrite {
 		blankSheetIndex, err := t.File.NewSheet("blank")
-		t.fatalIfNotNil(err)
+		if err != nil {
+			return log.SheetErrorOf(err, t.SheetName)
+		}
 		sheetIndex, err := t.File.GetSheetIndex(t.SheetName)
-		t.fatalIfNotNil(err)
+		if err != nil {
+			return log.SheetErrorOf(err, t.SheetName)
+		}
 		err = t.File.CopySheet(blankSheetIndex, sheetIndex)
-		t.fatalIfNotNil(err)
+		if err != nil {
+			return log.SheetErrorOf(err, t.SheetName)
+		}
 		err = t.File.DeleteSheet("blank")
-		t.fatalIfNotNil(err)
+		if err != nil {
+			return log.SheetErrorOf(err, t.SheetName)
+		}
 	}
 
 	if params.ColumnWidth > 0 {
@@ -50,23 +46,33 @@ func (t *Table) WriteRows(params WriteRowsParams) {
 			t.getColumn(t.ColumnCount-1),
 			params.ColumnWidth,
 		)
-		t.fatalIfNotNil(err)
+		if err != nil {
+			return log.SheetErrorOf(err, t.SheetName)
+		}
 	}
 
 	if params.ColumnStyler != nil {
 		for c := 0; c < t.ColumnCount; c++ {
 			if style, ok := params.ColumnStyler(c); ok {
 				err := t.File.SetColStyle(t.SheetName, t.getColumn(c), style)
-				t.fatalIfNotNil(err)
+				if err != nil {
+					return log.SheetErrorOf(err, t.SheetName)
+				}
 			}
 		}
 	}
 
 	if params.RowStyler != nil {
-		for r := -1; r < params.RowCount; r++ {
+		startRow := -1
+		if params.StartRow > 0 {
+			startRow = params.StartRow
+		}
+		for r := startRow; r < params.RowCount; r++ {
 			if style, ok := params.RowStyler(r); ok {
 				err := t.File.SetCellStyle(t.SheetName, t.GetCell(r, 0), t.GetCell(r, t.ColumnCount-1), style)
-				t.fatalIfNotNil(err)
+				if err != nil {
+					return log.SheetErrorOf(err, t.SheetName)
+				}
 			}
 		}
 	}
@@ -74,13 +80,17 @@ func (t *Table) WriteRows(params WriteRowsParams) {
 	mergeCount := 1
 	if params.HeaderWriter != nil {
 		params.HeaderWriter(cells, &mergeCount)
-		t.writeRowCells(-1, cells, mergeCount)
+		if err := t.writeRowCells(-1, cells, mergeCount); err != nil {
+			return err
+		}
 		resetWCells(cells)
 	}
 
-	for r := 0; params.RowWriter == nil || r < params.RowCount; r++ {
+	for r := params.StartRow; params.RowWriter == nil || r < params.RowCount; r++ {
 		params.RowWriter(r, cells)
-		t.writeRowCells(r, cells, 1)
+		if err := t.writeRowCells(r, cells, 1); err != nil {
+			return err
+		}
 		resetWCells(cells)
 	}
 
@@ -88,11 +98,15 @@ func (t *Table) WriteRows(params WriteRowsParams) {
 		rangeRef := fmt.Sprintf("%s:%s",
 			t.GetCell(condStyle.StartRow, condStyle.StartCol), t.GetCell(condStyle.EndRow, condStyle.EndCol))
 		err := t.File.SetConditionalFormat(t.SheetName, rangeRef, condStyle.Options)
-		t.fatalIfNotNil(err)
+		if err != nil {
+			return log.SheetErrorOf(err, t.SheetName)
+		}
 	}
+
+	return nil
 }
 
-func (t *Table) writeRowCells(row int, cells []*WCell, multiplier int) {
+func (t *Table) writeRowCells(row int, cells []*WCell, multiplier int) error {
 	var err error
 	for i := 0; i < len(cells); i++ {
 		cell := t.GetCell(row, i)
@@ -101,20 +115,35 @@ func (t *Table) writeRowCells(row int, cells []*WCell, multiplier int) {
 		}
 
 		err = t.File.MergeCell(t.SheetName, cell, t.GetCell(row, i+multiplier-1))
-		t.fatalIfNotNil(err)
+		if err != nil {
+			return log.SheetErrorOf(err, t.SheetName)
+		}
 
 		err = t.File.SetCellValue(t.SheetName, cell, cells[i].Value)
-		t.fatalIfNotNilWithCell(err, cell)
+		if err != nil {
+			return log.CellErrorOf(err, t.SheetName, cell)
+		}
 
 		err = t.File.SetCellFormula(t.SheetName, cell, cells[i].Formula)
-		t.fatalIfNotNilWithCell(err, cell)
+		if err != nil {
+			return log.CellErrorOf(err, t.SheetName, cell)
+		}
 
 		if cells[i].Style != nil {
 			style := *cells[i].Style
 			err = t.File.SetCellStyle(t.SheetName, cell, cell, style)
-			t.fatalIfNotNilWithCell(err, cell)
+			if err != nil {
+				return log.CellErrorOf(err, t.SheetName, cell)
+			}
 		}
 	}
+
+	return nil
+}
+
+func (t *Table) SetColumnsVisible(startCol, endCol int, visible bool) error {
+	err := t.File.SetColVisible(t.SheetName, fmt.Sprintf("%s:%s", t.getColumn(startCol), t.getColumn(endCol)), visible)
+	return log.SheetErrorOf(err, t.SheetName)
 }
 
 func (t *Table) GetCell(rowN, colN int) string {
@@ -154,7 +183,7 @@ func (t *Table) getColumn(colN int) string {
 	return string(colDigits)
 }
 
-func (t *Table) ReadRows(params ReadRowsParams) {
+func (t *Table) ReadRows(params ReadRowsParams) error {
 	cells := make([]*RCell, t.ColumnCount)
 	for i := range cells {
 		cells[i] = &RCell{}
@@ -165,21 +194,27 @@ func (t *Table) ReadRows(params ReadRowsParams) {
 		i = -1
 	}
 	for ; params.RowReader == nil || params.UnknownRowCount || i < params.RowCount; i++ {
-		t.readRowCells(i, cells)
+		if err := t.readRowCells(i, cells); err != nil {
+			return err
+		}
 		if allValuesEmpty(cells) {
 			break
 		}
 		params.RowReader(i, cells)
 		resetRCells(cells)
 	}
+
+	return nil
 }
 
-func (t *Table) readRowCells(row int, cells []*RCell) {
+func (t *Table) readRowCells(row int, cells []*RCell) error {
 	for i := 0; i < len(cells); i++ {
 		cell := t.GetCell(row, i)
 
 		formula, err := t.File.GetCellFormula(t.SheetName, cell)
-		t.fatalIfNotNilWithCell(err, cell)
+		if err != nil {
+			return log.CellErrorOf(err, t.SheetName, cell)
+		}
 		cells[i].Formula = formula
 
 		var value string
@@ -190,7 +225,11 @@ func (t *Table) readRowCells(row int, cells []*RCell) {
 		if formula == "" || err != nil {
 			value, err = t.File.GetCellValue(t.SheetName, cell)
 		}
-		t.fatalIfNotNilWithCell(err, cell)
+		if err != nil {
+			return log.CellErrorOf(err, t.SheetName, cell)
+		}
 		cells[i].Value = value
 	}
+
+	return nil
 }
@@ -5,10 +5,12 @@ import "github.com/xuri/excelize/v2"
 type StylerFunc func(n int) (int, bool)
 
 type WriteRowsParams struct {
-	HeaderWriter      func(cells []*WCell, mergeCount *int)
-	RowWriter         func(rowNumber int, cells []*WCell)
-	ColumnWidth       float64
-	RowCount          int
+	HeaderWriter func(cells []*WCell, mergeCount *int)
+	RowWriter    func(rowNumber int, cells []*WCell)
+	ColumnWidth  float64
+	RowCount     int
+	// StartRow is the first row written by RowWriter and styled by RowStyler. Rows before it are left untouched.
+	StartRow          int
 	ColumnStyler      StylerFunc
 	RowStyler         StylerFunc
 	ConditionalStyles []*ConditionalStyle
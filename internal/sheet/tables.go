@@ -12,7 +12,6 @@ const (
 	expensesLeftSideRowOffset  = 3
 	expensesLeftSideColOffset  = 1
 	expensesRightSideRowOffset = 2
-	expensesRightSideColOffset = 5
 
 	transactionsRowOffset = 2
 	transactionsColOffset = 1
@@ -25,6 +24,16 @@ const (
 
 	baseStateRowOffset = 2
 	baseStateColOffset = 1
+
+	ratesRowOffset = 2
+	ratesColOffset = 1
+
+	constraintsRowOffset  = 2
+	constraintsColOffset  = 1
+	blockedPairsRowOffset = 6
+	blockedPairsColOffset = 1
+	preferencesRowOffset  = 6
+	preferencesColOffset  = 4
 )
 
 func newMembersTable(file *excelize.File) *table.Table {
@@ -33,37 +42,48 @@ func newMembersTable(file *excelize.File) *table.Table {
 		SheetName:    membersSheet,
 		RowOffset:    membersRowOffset,
 		ColumnOffset: membersColOffset,
-		ColumnCount:  2,
+		ColumnCount:  3,
 	}
 }
 
-func newExpensesLeftTable(file *excelize.File) *table.Table {
+func newExpensesLeftTable(file *excelize.File, layout *expensesLayout) *table.Table {
 	return &table.Table{
 		File:         file,
 		SheetName:    expensesSheet,
 		RowOffset:    expensesLeftSideRowOffset,
 		ColumnOffset: expensesLeftSideColOffset,
-		ColumnCount:  4,
+		ColumnCount:  len(layout.commonHeaders),
 	}
 }
 
-func newExpensesRightTable(file *excelize.File, membersCount int) *table.Table {
+// newExpensesHelpTable returns the table whose header is the help text above the common columns of the expenses.
+func newExpensesHelpTable(file *excelize.File, layout *expensesLayout) *table.Table {
 	return &table.Table{
 		File:         file,
 		SheetName:    expensesSheet,
 		RowOffset:    expensesRightSideRowOffset,
-		ColumnOffset: expensesRightSideColOffset,
-		ColumnCount:  membersCount * 2,
+		ColumnOffset: expensesLeftSideColOffset,
+		ColumnCount:  len(layout.commonHeaders),
 	}
 }
 
-func newExpensesFullTable(file *excelize.File, membersCount int) *table.Table {
+func newExpensesRightTable(file *excelize.File, layout *expensesLayout, membersCount int) *table.Table {
+	return &table.Table{
+		File:         file,
+		SheetName:    expensesSheet,
+		RowOffset:    expensesRightSideRowOffset,
+		ColumnOffset: expensesLeftSideColOffset + len(layout.commonHeaders),
+		ColumnCount:  membersCount * len(layout.memberHeaders),
+	}
+}
+
+func newExpensesFullTable(file *excelize.File, layout *expensesLayout, membersCount int) *table.Table {
 	return &table.Table{
 		File:         file,
 		SheetName:    expensesSheet,
 		RowOffset:    expensesRightSideRowOffset,
 		ColumnOffset: expensesLeftSideColOffset,
-		ColumnCount:  4 + membersCount*2,
+		ColumnCount:  len(layout.commonHeaders) + membersCount*len(layout.memberHeaders),
 	}
 }
 
@@ -73,7 +93,7 @@ func newTransactionsTable(file *excelize.File) *table.Table {
 		SheetName:    transactionsSheet,
 		RowOffset:    transactionsRowOffset,
 		ColumnOffset: transactionsColOffset,
-		ColumnCount:  4,
+		ColumnCount:  5,
 	}
 }
 
@@ -107,12 +127,72 @@ func newBaseStateTable(file *excelize.File, membersCount int) *table.Table {
 	}
 }
 
+func newRatesTable(file *excelize.File) *table.Table {
+	return &table.Table{
+		File:         file,
+		SheetName:    ratesSheet,
+		RowOffset:    ratesRowOffset,
+		ColumnOffset: ratesColOffset,
+		ColumnCount:  3,
+	}
+}
+
+func newConstraintsTable(file *excelize.File) *table.Table {
+	return &table.Table{
+		File:         file,
+		SheetName:    settlementConstraintsSheet,
+		RowOffset:    constraintsRowOffset,
+		ColumnOffset: constraintsColOffset,
+		ColumnCount:  2,
+	}
+}
+
+func newBlockedPairsTable(file *excelize.File) *table.Table {
+	return &table.Table{
+		File:         file,
+		SheetName:    settlementConstraintsSheet,
+		RowOffset:    blockedPairsRowOffset,
+		ColumnOffset: blockedPairsColOffset,
+		ColumnCount:  2,
+	}
+}
+
+func newPreferencesTable(file *excelize.File) *table.Table {
+	return &table.Table{
+		File:         file,
+		SheetName:    settlementConstraintsSheet,
+		RowOffset:    preferencesRowOffset,
+		ColumnOffset: preferencesColOffset,
+		ColumnCount:  2,
+	}
+}
+
 func newMetadataTable(file *excelize.File) *table.Table {
 	return &table.Table{
 		File:         file,
 		SheetName:    metadataSheet,
 		RowOffset:    1,
 		ColumnOffset: 1,
+		ColumnCount:  2,
+	}
+}
+
+func newExpensesMemberTable(file *excelize.File, layout *expensesLayout, memberIndex int) *table.Table {
+	return &table.Table{
+		File:         file,
+		SheetName:    expensesSheet,
+		RowOffset:    expensesRightSideRowOffset,
+		ColumnOffset: expensesLeftSideColOffset + len(layout.commonHeaders) + memberIndex*len(layout.memberHeaders),
+		ColumnCount:  len(layout.memberHeaders),
+	}
+}
+
+func newExpensesShareAmountTable(file *excelize.File, layout *expensesLayout, memberIndex int) *table.Table {
+	return &table.Table{
+		File:         file,
+		SheetName:    expensesSheet,
+		RowOffset:    expensesRightSideRowOffset,
+		ColumnOffset: expensesLeftSideColOffset + layout.fullColumn(memberIndex, shareAmountHeader),
 		ColumnCount:  1,
 	}
 }
@@ -0,0 +1,174 @@
+package storage
+
+import (
+	"encoding/csv"
+	"fmt"
+	"github.com/MeysamBavi/group-expense-manager/internal/model"
+	"github.com/MeysamBavi/group-expense-manager/pkg/ledger"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// csvFile is a table written by ExportCSV.
+type csvFile struct {
+	name   string
+	header []string
+	rows   func(s Store) ([][]string, error)
+}
+
+var csvFiles = []csvFile{
+	{
+		name:   "members.csv",
+		header: []string{"name", "card_number", "status"},
+		rows:   memberRows,
+	},
+	{
+		name: "expenses.csv",
+		header: []string{"expense_id", "time", "title", "payer", "total_amount", "currency", "split",
+			"member", "share_weight", "share_value", "paid", "share_amount"},
+		rows: expenseRows,
+	},
+	{
+		name:   "transactions.csv",
+		header: []string{"time", "receiver", "payer", "amount", "currency"},
+		rows:   transactionRows,
+	},
+	{
+		name:   "debt_matrix.csv",
+		header: []string{"debtor", "creditor", "amount"},
+		rows: func(s Store) ([][]string, error) {
+			return matrixRows(s.Ledger().Members, s.DebtMatrix()), nil
+		},
+	},
+	{
+		name:   "settlements.csv",
+		header: []string{"payer", "receiver", "amount"},
+		rows:   settlementRows,
+	},
+	{
+		name:   "base_state.csv",
+		header: []string{"debtor", "creditor", "amount"},
+		rows: func(s Store) ([][]string, error) {
+			return matrixRows(s.Ledger().Members, s.Ledger().BaseState), nil
+		},
+	},
+}
+
+// ExportCSV writes the data of a store as CSV files in a directory, which is created if needed. The debt matrix and
+// the settlements are written as they are in the store, so the store should be updated first.
+// The expenses are written in long format: one row for every share of every expense, with the share amount in the
+// base currency. Amounts are written in full precision.
+func ExportCSV(s Store, dir string) ([]string, error) {
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return nil, err
+	}
+
+	var fileNames []string
+	for _, f := range csvFiles {
+		rows, err := f.rows(s)
+		if err != nil {
+			return nil, err
+		}
+		fileName := filepath.Join(dir, f.name)
+		err = writeCSV(fileName, append([][]string{f.header}, rows...))
+		if err != nil {
+			return nil, err
+		}
+		fileNames = append(fileNames, fileName)
+	}
+	return fileNames, nil
+}
+
+func writeCSV(fileName string, records [][]string) error {
+	file, err := os.Create(fileName)
+	if err != nil {
+		return err
+	}
+	writer := csv.NewWriter(file)
+	err = writer.WriteAll(records)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+func memberRows(s Store) ([][]string, error) {
+	var rows [][]string
+	for _, member := range s.Ledger().Members {
+		rows = append(rows, []string{member.Name, member.CardNumber, member.Status()})
+	}
+	return rows, nil
+}
+
+func expenseRows(s Store) ([][]string, error) {
+	l := s.Ledger()
+	var rows [][]string
+	for i, expense := range l.Expenses {
+		shareAmounts, err := ledger.ComputeShareAmounts(l, expense)
+		if err != nil {
+			return nil, fmt.Errorf("expense %q: %w", expense.Title, err)
+		}
+		for j, share := range expense.Shares {
+			if share.ShareWeight == 0 && share.ShareValue.IsZero() && share.Paid.IsZero() {
+				continue
+			}
+			shareValue := ""
+			if expense.UsesShareValues() {
+				shareValue = share.ShareValue.String()
+			}
+			rows = append(rows, []string{
+				strconv.Itoa(i + 1),
+				timeString(expense.Time),
+				expense.Title,
+				expense.PayerName,
+				expense.Amount.String(),
+				expense.Currency,
+				string(expense.SplitMode),
+				share.MemberName,
+				strconv.Itoa(share.ShareWeight),
+				shareValue,
+				share.Paid.String(),
+				shareAmounts[j].String(),
+			})
+		}
+	}
+	return rows, nil
+}
+
+func transactionRows(s Store) ([][]string, error) {
+	var rows [][]string
+	for _, transaction := range s.Ledger().Transactions {
+		rows = append(rows, []string{
+			timeString(transaction.Time),
+			transaction.ReceiverName,
+			transaction.PayerName,
+			transaction.Amount.String(),
+			transaction.Currency,
+		})
+	}
+	return rows, nil
+}
+
+func settlementRows(s Store) ([][]string, error) {
+	var rows [][]string
+	for _, settlement := range s.Settlements() {
+		rows = append(rows, []string{settlement.PayerName, settlement.ReceiverName, settlement.Amount.String()})
+	}
+	return rows, nil
+}
+
+// matrixRows returns a matrix of debts in long format: one row for every pair of different members.
+func matrixRows(members []*model.Member, matrix [][]model.Amount) [][]string {
+	var rows [][]string
+	for i := range matrix {
+		for j := range matrix[i] {
+			if i == j {
+				continue
+			}
+			rows = append(rows, []string{members[i].Name, members[j].Name, matrix[i][j].String()})
+		}
+	}
+	return rows
+}
@@ -0,0 +1,445 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"github.com/MeysamBavi/group-expense-manager/internal/log"
+	"github.com/MeysamBavi/group-expense-manager/internal/model"
+	"github.com/MeysamBavi/group-expense-manager/internal/sheet"
+	"github.com/MeysamBavi/group-expense-manager/internal/sheet/store"
+	"github.com/MeysamBavi/group-expense-manager/pkg/ledger"
+	"strings"
+)
+
+const (
+	settingsSection     = "settings"
+	membersSection      = "members"
+	ratesSection        = "rates"
+	expensesSection     = "expenses"
+	transactionsSection = "transactions"
+	baseStateSection    = "baseState"
+	debtMatrixSection   = "debtMatrix"
+	settlementsSection  = "settlements"
+	constraintsSection  = "settlementConstraints"
+)
+
+// document is the data of a group in plain values, as kept in JSON files and SQLite databases. Amounts are kept as
+// decimal strings, so that they are not rounded.
+type document struct {
+	Settings     settingsRecord      `json:"settings"`
+	Members      []memberRecord      `json:"members"`
+	Rates        []rateRecord        `json:"rates"`
+	Expenses     []expenseRecord     `json:"expenses"`
+	Transactions []transactionRecord `json:"transactions"`
+	BaseState    [][]string          `json:"baseState"`
+	DebtMatrix   [][]string          `json:"debtMatrix"`
+	Settlements  []transactionRecord `json:"settlements"`
+	Constraints  *constraintsRecord  `json:"settlementConstraints,omitempty"`
+}
+
+type settingsRecord struct {
+	FractionDigits int    `json:"fractionDigits"`
+	Rounding       string `json:"rounding"`
+	BaseCurrency   string `json:"baseCurrency"`
+	Settlement     string `json:"settlement,omitempty"`
+	PeriodStart    string `json:"periodStart,omitempty"`
+	PeriodEnd      string `json:"periodEnd,omitempty"`
+	PreviousPeriod string `json:"previousPeriod,omitempty"`
+	NextPeriod     string `json:"nextPeriod,omitempty"`
+}
+
+// constraintsRecord is the settlement constraints. The empty fields are not used.
+type constraintsRecord struct {
+	Treasurer string       `json:"treasurer,omitempty"`
+	Minimum   string       `json:"minimum,omitempty"`
+	Blocked   []pairRecord `json:"blocked,omitempty"`
+	Preferred []pairRecord `json:"preferred,omitempty"`
+}
+
+type pairRecord struct {
+	Payer    string `json:"payer"`
+	Receiver string `json:"receiver"`
+}
+
+type memberRecord struct {
+	Name       string `json:"name"`
+	CardNumber string `json:"cardNumber"`
+	Status     string `json:"status,omitempty"`
+}
+
+type rateRecord struct {
+	Date     string `json:"date,omitempty"`
+	Currency string `json:"currency"`
+	Rate     string `json:"rate"`
+}
+
+type expenseRecord struct {
+	Time     string        `json:"time"`
+	Title    string        `json:"title"`
+	Payer    string        `json:"payer,omitempty"`
+	Amount   string        `json:"amount"`
+	Currency string        `json:"currency,omitempty"`
+	Split    string        `json:"split,omitempty"`
+	Shares   []shareRecord `json:"shares"`
+}
+
+// shareRecord is the share of a member in an expense. The members without a share are omitted.
+type shareRecord struct {
+	Member string `json:"member"`
+	Weight int    `json:"weight,omitempty"`
+	Value  string `json:"value,omitempty"`
+	Paid   string `json:"paid,omitempty"`
+}
+
+type transactionRecord struct {
+	Time     string `json:"time,omitempty"`
+	Receiver string `json:"receiver"`
+	Payer    string `json:"payer"`
+	Amount   string `json:"amount"`
+	Currency string `json:"currency,omitempty"`
+}
+
+// decodeDocument parses and validates a document. It does not stop at the first invalid value; all the errors are
+// returned together.
+func decodeDocument(doc *document) (*memoryStore, error) {
+	var errs log.ErrorList
+	s := &memoryStore{
+		settings: sheet.DefaultSettings(),
+		members:  store.NewMemberStore(),
+	}
+
+	var err error
+	s.settings.FractionDigits = doc.Settings.FractionDigits
+	errs.Add(log.CellErrorOf(s.settings.Validate(), settingsSection, "fractionDigits"))
+	if doc.Settings.Rounding != "" {
+		s.settings.Rounding, err = model.ParseRoundingPolicy(doc.Settings.Rounding)
+		errs.Add(log.CellErrorOf(err, settingsSection, "rounding"))
+	}
+	s.settings.BaseCurrency = strings.ToUpper(strings.TrimSpace(doc.Settings.BaseCurrency))
+	s.settings.Settlement, err = ledger.ParseSettlementMode(doc.Settings.Settlement)
+	errs.Add(log.CellErrorOf(err, settingsSection, "settlement"))
+	s.settings.PeriodStart, err = sheet.ParsePeriodTime(doc.Settings.PeriodStart)
+	errs.Add(log.CellErrorOf(err, settingsSection, "periodStart"))
+	s.settings.PeriodEnd, err = sheet.ParsePeriodTime(doc.Settings.PeriodEnd)
+	errs.Add(log.CellErrorOf(err, settingsSection, "periodEnd"))
+	s.settings.PreviousPeriod = doc.Settings.PreviousPeriod
+	s.settings.NextPeriod = doc.Settings.NextPeriod
+
+	for i, m := range doc.Members {
+		deactivated, err := model.ParseMemberStatus(m.Status)
+		errs.Add(log.CellErrorOf(err, membersSection, elementName(i, "status")))
+		err = s.members.AddMember(&model.Member{
+			Name:        strings.TrimSpace(m.Name),
+			CardNumber:  strings.TrimSpace(m.CardNumber),
+			Deactivated: deactivated,
+		})
+		errs.Add(log.CellErrorOf(err, membersSection, elementName(i, "name")))
+	}
+	// the other sections refer to the members
+	if err := errs.Err(); err != nil {
+		return nil, err
+	}
+
+	s.rates = store.NewRateStore(s.settings.BaseCurrency)
+	for i, r := range doc.Rates {
+		theTime, timeErr := model.ParseTime(r.Date)
+		errs.Add(log.CellErrorOf(timeErr, ratesSection, elementName(i, "date")))
+		rate, rateErr := model.ParseAmount(r.Rate)
+		errs.Add(log.CellErrorOf(rateErr, ratesSection, elementName(i, "rate")))
+		if timeErr == nil && rateErr == nil {
+			err := s.rates.AddRate(strings.TrimSpace(r.Currency), theTime, rate)
+			errs.Add(log.CellErrorOf(err, ratesSection, elementName(i, "currency")))
+		}
+	}
+
+	for i, e := range doc.Expenses {
+		expense, err := decodeExpense(e, i)
+		if err == nil {
+			err = log.CellErrorOf(store.PrepareExpense(expense, s.members, s.rates), expensesSection, elementName(i, ""))
+		}
+		errs.Add(err)
+		s.expenses = append(s.expenses, expense)
+	}
+
+	for i, t := range doc.Transactions {
+		transaction, err := decodeTransaction(t, transactionsSection, i)
+		if err == nil {
+			err = log.CellErrorOf(store.PrepareTransaction(transaction, s.members, s.rates), transactionsSection, elementName(i, ""))
+		}
+		errs.Add(err)
+		s.transactions = append(s.transactions, transaction)
+	}
+
+	s.baseState, err = decodeMatrix(doc.BaseState, s.members.Count(), baseStateSection)
+	errs.Add(err)
+	s.debtMatrix, err = decodeMatrix(doc.DebtMatrix, s.members.Count(), debtMatrixSection)
+	errs.Add(err)
+	for i, t := range doc.Settlements {
+		settlement, err := decodeTransaction(t, settlementsSection, i)
+		errs.Add(err)
+		s.settlements = append(s.settlements, settlement)
+	}
+	if doc.Constraints != nil {
+		s.constraints, err = decodeConstraints(doc.Constraints, s.members)
+		errs.Add(err)
+	}
+
+	if err := errs.Err(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// document returns the data of the store as a document.
+func (s *memoryStore) document() *document {
+	doc := &document{
+		Settings: settingsRecord{
+			FractionDigits: s.settings.FractionDigits,
+			Rounding:       string(s.settings.Rounding),
+			BaseCurrency:   s.settings.BaseCurrency,
+			Settlement:     string(s.settings.Settlement),
+			PeriodStart:    timeString(s.settings.PeriodStart),
+			PeriodEnd:      timeString(s.settings.PeriodEnd),
+			PreviousPeriod: s.settings.PreviousPeriod,
+			NextPeriod:     s.settings.NextPeriod,
+		},
+		Members:      make([]memberRecord, 0, s.members.Count()),
+		Rates:        make([]rateRecord, 0),
+		Expenses:     make([]expenseRecord, 0, len(s.expenses)),
+		Transactions: make([]transactionRecord, 0, len(s.transactions)),
+		BaseState:    encodeMatrix(s.baseState),
+		DebtMatrix:   encodeMatrix(s.debtMatrix),
+		Settlements:  make([]transactionRecord, 0, len(s.settlements)),
+	}
+	s.members.Range(func(_ int, member *model.Member) {
+		doc.Members = append(doc.Members, memberRecord{
+			Name:       member.Name,
+			CardNumber: member.CardNumber,
+			Status:     member.Status(),
+		})
+	})
+	s.rates.Range(func(currency string, t model.Time, value model.Amount) {
+		doc.Rates = append(doc.Rates, rateRecord{
+			Date:     t.String(),
+			Currency: currency,
+			Rate:     value.String(),
+		})
+	})
+	for _, expense := range s.expenses {
+		doc.Expenses = append(doc.Expenses, encodeExpense(expense))
+	}
+	for _, transaction := range s.transactions {
+		doc.Transactions = append(doc.Transactions, encodeTransaction(transaction))
+	}
+	for _, settlement := range s.settlements {
+		doc.Settlements = append(doc.Settlements, encodeTransaction(settlement))
+	}
+	if !s.constraints.IsEmpty() {
+		doc.Constraints = encodeConstraints(s.constraints)
+	}
+	return doc
+}
+
+func encodeConstraints(constraints *ledger.SettlementConstraints) *constraintsRecord {
+	c := &constraintsRecord{Treasurer: constraints.Treasurer}
+	if constraints.MinimumAmount.IsPositive() {
+		c.Minimum = constraints.MinimumAmount.String()
+	}
+	for _, pair := range constraints.Blocked {
+		c.Blocked = append(c.Blocked, pairRecord{Payer: pair.PayerName, Receiver: pair.ReceiverName})
+	}
+	for _, pair := range constraints.Preferred {
+		c.Preferred = append(c.Preferred, pairRecord{Payer: pair.PayerName, Receiver: pair.ReceiverName})
+	}
+	return c
+}
+
+// decodeConstraints parses and validates the settlement constraints. It does not stop at the first invalid value; all
+// the errors are returned together.
+func decodeConstraints(c *constraintsRecord, members *store.MemberStore) (*ledger.SettlementConstraints, error) {
+	var errs log.ErrorList
+	constraints := &ledger.SettlementConstraints{MinimumAmount: model.AmountZero()}
+	memberName := func(name, field string) string {
+		member, ok := members.GetMemberByName(strings.TrimSpace(name))
+		if !ok {
+			errs.Add(log.CellErrorOf(fmt.Errorf("found no member with name %q", name), constraintsSection, field))
+			return ""
+		}
+		return member.Name
+	}
+
+	if strings.TrimSpace(c.Treasurer) != "" {
+		constraints.Treasurer = memberName(c.Treasurer, "treasurer")
+	}
+	minimum, err := model.ParseAmount(c.Minimum)
+	if err == nil && minimum.IsNegative() {
+		err = errors.New("minimum settlement should not be negative")
+	}
+	errs.Add(log.CellErrorOf(err, constraintsSection, "minimum"))
+	if err == nil {
+		constraints.MinimumAmount = minimum
+	}
+	decodePairs := func(pairs []pairRecord, field string, add func(payer, receiver string)) {
+		for i, pair := range pairs {
+			field := fmt.Sprintf("%s[%d]", field, i)
+			payer := memberName(pair.Payer, field+".payer")
+			receiver := memberName(pair.Receiver, field+".receiver")
+			if payer == "" || receiver == "" {
+				continue
+			}
+			if payer == receiver {
+				errs.Add(log.CellErrorOf(errors.New("payer and receiver should be different members"), constraintsSection, field))
+				continue
+			}
+			add(payer, receiver)
+		}
+	}
+	decodePairs(c.Blocked, "blocked", func(payer, receiver string) {
+		constraints.Blocked = append(constraints.Blocked, ledger.BlockedPair{PayerName: payer, ReceiverName: receiver})
+	})
+	decodePairs(c.Preferred, "preferred", func(payer, receiver string) {
+		constraints.Preferred = append(constraints.Preferred, ledger.PreferredPair{PayerName: payer, ReceiverName: receiver})
+	})
+	return constraints, errs.Err()
+}
+
+func encodeExpense(expense *model.Expense) expenseRecord {
+	e := expenseRecord{
+		Time:     timeString(expense.Time),
+		Title:    expense.Title,
+		Payer:    expense.PayerName,
+		Amount:   expense.Amount.String(),
+		Currency: expense.Currency,
+		Split:    string(expense.SplitMode),
+		Shares:   make([]shareRecord, 0, len(expense.Shares)),
+	}
+	for _, share := range expense.Shares {
+		if share.ShareWeight == 0 && share.ShareValue.IsZero() && share.Paid.IsZero() {
+			continue
+		}
+		s := shareRecord{
+			Member: share.MemberName,
+			Weight: share.ShareWeight,
+		}
+		if !share.ShareValue.IsZero() {
+			s.Value = share.ShareValue.String()
+		}
+		if !share.Paid.IsZero() {
+			s.Paid = share.Paid.String()
+		}
+		e.Shares = append(e.Shares, s)
+	}
+	return e
+}
+
+func decodeExpense(e expenseRecord, index int) (*model.Expense, error) {
+	var errs log.ErrorList
+	theTime, err := model.ParseTime(e.Time)
+	errs.Add(log.CellErrorOf(err, expensesSection, elementName(index, "time")))
+	amount, err := model.ParseAmount(e.Amount)
+	errs.Add(log.CellErrorOf(err, expensesSection, elementName(index, "amount")))
+	mode, err := model.ParseSplitMode(e.Split)
+	errs.Add(log.CellErrorOf(err, expensesSection, elementName(index, "split")))
+
+	expense := &model.Expense{
+		Title:     e.Title,
+		Time:      theTime,
+		PayerName: strings.TrimSpace(e.Payer),
+		Amount:    amount,
+		Currency:  strings.TrimSpace(e.Currency),
+		SplitMode: mode,
+	}
+	for i, s := range e.Shares {
+		share := model.Share{
+			MemberName:  strings.TrimSpace(s.Member),
+			ShareWeight: s.Weight,
+		}
+		if share.ShareWeight < 0 {
+			errs.Add(log.CellErrorOf(fmt.Errorf("share weight %d is negative", s.Weight),
+				expensesSection, elementName(index, fmt.Sprintf("shares[%d].weight", i))))
+		}
+		share.ShareValue, err = model.ParseShareValue(s.Value)
+		errs.Add(log.CellErrorOf(err, expensesSection, elementName(index, fmt.Sprintf("shares[%d].value", i))))
+		share.Paid, err = model.ParseAmount(s.Paid)
+		errs.Add(log.CellErrorOf(err, expensesSection, elementName(index, fmt.Sprintf("shares[%d].paid", i))))
+		expense.Shares = append(expense.Shares, share)
+	}
+	return expense, errs.Err()
+}
+
+func encodeTransaction(transaction *model.Transaction) transactionRecord {
+	return transactionRecord{
+		Time:     timeString(transaction.Time),
+		Receiver: transaction.ReceiverName,
+		Payer:    transaction.PayerName,
+		Amount:   transaction.Amount.String(),
+		Currency: transaction.Currency,
+	}
+}
+
+func decodeTransaction(t transactionRecord, section string, index int) (*model.Transaction, error) {
+	var errs log.ErrorList
+	theTime, err := model.ParseTime(t.Time)
+	errs.Add(log.CellErrorOf(err, section, elementName(index, "time")))
+	amount, err := model.ParseAmount(t.Amount)
+	errs.Add(log.CellErrorOf(err, section, elementName(index, "amount")))
+	return &model.Transaction{
+		Time:         theTime,
+		ReceiverName: strings.TrimSpace(t.Receiver),
+		PayerName:    strings.TrimSpace(t.Payer),
+		Amount:       amount,
+		Currency:     strings.TrimSpace(t.Currency),
+	}, errs.Err()
+}
+
+func encodeMatrix(matrix [][]model.Amount) [][]string {
+	result := make([][]string, len(matrix))
+	for i := range matrix {
+		result[i] = make([]string, len(matrix[i]))
+		for j := range matrix[i] {
+			result[i][j] = matrix[i][j].String()
+		}
+	}
+	return result
+}
+
+// decodeMatrix parses a square matrix of amounts. An empty matrix is considered all zeros.
+func decodeMatrix(matrix [][]string, size int, section string) ([][]model.Amount, error) {
+	result := ledger.EmptyMatrix(size)
+	if len(matrix) == 0 {
+		return result, nil
+	}
+	if len(matrix) != size {
+		return result, log.SheetErrorOf(fmt.Errorf("found %d rows instead of %d", len(matrix), size), section)
+	}
+
+	var errs log.ErrorList
+	for i := range matrix {
+		if len(matrix[i]) != size {
+			errs.Add(log.CellErrorOf(fmt.Errorf("found %d columns instead of %d", len(matrix[i]), size),
+				section, fmt.Sprintf("[%d]", i)))
+			continue
+		}
+		for j := range matrix[i] {
+			amount, err := model.ParseAmount(matrix[i][j])
+			errs.Add(log.CellErrorOf(err, section, fmt.Sprintf("[%d][%d]", i, j)))
+			result[i][j] = amount
+		}
+	}
+	return result, errs.Err()
+}
+
+// elementName returns the location of a field of an element of a section, like "[2].amount".
+func elementName(index int, field string) string {
+	if field == "" {
+		return fmt.Sprintf("[%d]", index)
+	}
+	return fmt.Sprintf("[%d].%s", index, field)
+}
+
+func timeString(t model.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.String()
+}
@@ -0,0 +1,199 @@
+package storage
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"github.com/MeysamBavi/group-expense-manager/internal/log"
+	"io"
+	"os"
+	"strings"
+)
+
+// ExpenseColumns are the columns of a CSV file of expenses, matching the fields of ExpenseFields.
+var ExpenseColumns = []string{"time", "title", "payer", "amount", "currency", "mode", "split", "paid"}
+
+// TransactionColumns are the columns of a CSV file of transactions, matching the fields of TransactionFields.
+var TransactionColumns = []string{"time", "receiver", "payer", "amount", "currency"}
+
+// ImportExpensesCSV reads expenses from a CSV file and adds the valid ones to a store. The file must start with a
+// header naming some of ExpenseColumns in any order; title and amount are required, and the amount of every row should
+// be positive like ParseExpense requires.
+// It returns the number of added expenses. The problems of all the invalid rows are returned together in a
+// log.ValidationError, with their line numbers; any other error means that no expense has been added.
+func ImportExpensesCSV(s Store, fileName string) (int, error) {
+	rows, err := readCSVRows(fileName, ExpenseColumns, []string{"title", "amount"})
+	if err != nil {
+		return 0, err
+	}
+
+	return importRows(rows, func(row csvRow) error {
+		expense, err := ParseExpense(s, ExpenseFields{
+			Time:     row.values["time"],
+			Title:    row.values["title"],
+			Payer:    row.values["payer"],
+			Amount:   row.values["amount"],
+			Currency: row.values["currency"],
+			Mode:     row.values["mode"],
+			Split:    row.values["split"],
+			Paid:     row.values["paid"],
+		})
+		if err != nil {
+			return err
+		}
+		return s.AddExpense(expense)
+	})
+}
+
+// ImportTransactionsCSV reads transactions from a CSV file and adds the valid ones to a store. The file must start
+// with a header naming some of TransactionColumns in any order; receiver, payer and amount are required, and the
+// amount of every row should be positive like ParseTransaction requires.
+// The result is the same as ImportExpensesCSV.
+func ImportTransactionsCSV(s Store, fileName string) (int, error) {
+	rows, err := readCSVRows(fileName, TransactionColumns, []string{"receiver", "payer", "amount"})
+	if err != nil {
+		return 0, err
+	}
+
+	return importRows(rows, func(row csvRow) error {
+		transaction, err := ParseTransaction(TransactionFields{
+			Time:     row.values["time"],
+			Receiver: row.values["receiver"],
+			Payer:    row.values["payer"],
+			Amount:   row.values["amount"],
+			Currency: row.values["currency"],
+		})
+		if err != nil {
+			return err
+		}
+		return s.AddTransaction(transaction)
+	})
+}
+
+// csvRow is a row of an imported CSV file, with its values by column name.
+type csvRow struct {
+	fileName string
+	line     int
+	values   map[string]string
+	err      error
+}
+
+func (r csvRow) errorOf(err error) error {
+	return log.CellErrorOf(err, r.fileName, fmt.Sprintf("line %d", r.line))
+}
+
+// importRows adds the rows one by one and collects the errors of the invalid ones.
+func importRows(rows []csvRow, add func(row csvRow) error) (int, error) {
+	var errs log.ErrorList
+	added := 0
+	for _, row := range rows {
+		err := row.err
+		if err == nil {
+			err = add(row)
+		}
+		if err != nil {
+			errs.Add(row.errorOf(err))
+			continue
+		}
+		added++
+	}
+	return added, errs.Err()
+}
+
+// readCSVRows reads the rows of a CSV file with a header. Blank rows are skipped and rows with a wrong number of
+// values are returned with an error.
+func readCSVRows(fileName string, columns, required []string) ([]csvRow, error) {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := parseCSVRows(file, fileName, columns, required)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	return rows, err
+}
+
+func parseCSVRows(r io.Reader, fileName string, columns, required []string) ([]csvRow, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, log.SheetErrorOf(errors.New("found no header"), fileName)
+	}
+	if err != nil {
+		return nil, err
+	}
+	header, err = parseHeader(header, columns, required)
+	if err != nil {
+		return nil, log.SheetErrorOf(err, fileName)
+	}
+
+	var rows []csvRow
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if isBlank(record) {
+			continue
+		}
+		line, _ := reader.FieldPos(0)
+		row := csvRow{fileName: fileName, line: line, values: make(map[string]string)}
+		if len(record) != len(header) {
+			row.err = fmt.Errorf("found %d values, expected %d", len(record), len(header))
+		}
+		for i := 0; i < len(record) && i < len(header); i++ {
+			row.values[header[i]] = record[i]
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// parseHeader returns the normalized column names of a header, checking them against the valid and required columns.
+func parseHeader(header []string, columns, required []string) ([]string, error) {
+	result := make([]string, len(header))
+	seen := make(map[string]bool)
+	for i, name := range header {
+		if i == 0 {
+			name = strings.TrimPrefix(name, "\ufeff")
+		}
+		name = strings.ToLower(strings.TrimSpace(name))
+		if !contains(columns, name) {
+			return nil, fmt.Errorf("unknown column %q; valid columns are %s", name, strings.Join(columns, ", "))
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("column %q is repeated", name)
+		}
+		seen[name] = true
+		result[i] = name
+	}
+	for _, name := range required {
+		if !seen[name] {
+			return nil, fmt.Errorf("column %q is required", name)
+		}
+	}
+	return result, nil
+}
+
+func isBlank(record []string) bool {
+	for _, value := range record {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,39 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// jsonStore keeps the data of a group in a plain JSON file.
+type jsonStore struct {
+	*memoryStore
+}
+
+// openJSONStore reads a JSON file. It does not stop at the first invalid value; all the errors are returned together.
+func openJSONStore(fileName string) (*jsonStore, error) {
+	content, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+	var doc document
+	err = json.Unmarshal(content, &doc)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse %q: %w", fileName, err)
+	}
+
+	s, err := decodeDocument(&doc)
+	if err != nil {
+		return nil, err
+	}
+	return &jsonStore{s}, nil
+}
+
+func (s *jsonStore) SaveAs(fileName string) error {
+	content, err := json.MarshalIndent(s.document(), "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(fileName, append(content, '\n'), 0644)
+}
@@ -0,0 +1,114 @@
+package storage
+
+import (
+	"github.com/MeysamBavi/group-expense-manager/internal/model"
+	"github.com/MeysamBavi/group-expense-manager/internal/sheet"
+	"github.com/MeysamBavi/group-expense-manager/internal/sheet/store"
+	"github.com/MeysamBavi/group-expense-manager/pkg/ledger"
+)
+
+// memoryStore holds the data of a group in memory. The stores that keep the data as a document embed it and only
+// implement SaveAs.
+type memoryStore struct {
+	settings     *sheet.Settings
+	members      *store.MemberStore
+	rates        *store.RateStore
+	expenses     []*model.Expense
+	transactions []*model.Transaction
+	baseState    [][]model.Amount
+	debtMatrix   [][]model.Amount
+	settlements  []*model.Transaction
+	constraints  *ledger.SettlementConstraints
+}
+
+func newMemoryStore(members *store.MemberStore, settings *sheet.Settings) *memoryStore {
+	return &memoryStore{
+		settings:   settings,
+		members:    members,
+		rates:      store.NewRateStore(settings.BaseCurrency),
+		baseState:  ledger.EmptyMatrix(members.Count()),
+		debtMatrix: ledger.EmptyMatrix(members.Count()),
+	}
+}
+
+func (s *memoryStore) Ledger() *ledger.Ledger {
+	members := make([]*model.Member, 0, s.members.Count())
+	s.members.Range(func(_ int, member *model.Member) {
+		members = append(members, member)
+	})
+	return &ledger.Ledger{
+		Members:        members,
+		Expenses:       s.expenses,
+		Transactions:   s.transactions,
+		BaseState:      s.baseState,
+		FractionDigits: s.settings.FractionDigits,
+		Rounding:       s.settings.Rounding,
+		Rates:          s.rates,
+	}
+}
+
+func (s *memoryStore) Settings() *sheet.Settings {
+	return s.settings
+}
+
+func (s *memoryStore) SetSettings(settings *sheet.Settings) error {
+	s.settings = settings
+	return nil
+}
+
+func (s *memoryStore) SettlementConstraints() *ledger.SettlementConstraints {
+	return s.constraints
+}
+
+func (s *memoryStore) SetSettlementConstraints(constraints *ledger.SettlementConstraints) error {
+	s.constraints = constraints
+	return nil
+}
+
+func (s *memoryStore) Rates() *store.RateStore {
+	return s.rates
+}
+
+func (s *memoryStore) DebtMatrix() [][]model.Amount {
+	return s.debtMatrix
+}
+
+func (s *memoryStore) Settlements() []*model.Transaction {
+	return s.settlements
+}
+
+func (s *memoryStore) AddExpense(expense *model.Expense) error {
+	err := store.PrepareExpense(expense, s.members, s.rates)
+	if err != nil {
+		return err
+	}
+	s.expenses = append(s.expenses, expense)
+	return nil
+}
+
+func (s *memoryStore) AddTransaction(transaction *model.Transaction) error {
+	err := store.PrepareTransaction(transaction, s.members, s.rates)
+	if err != nil {
+		return err
+	}
+	s.transactions = append(s.transactions, transaction)
+	return nil
+}
+
+func (s *memoryStore) ReplaceRecords(rates *store.RateStore, expenses []*model.Expense, transactions []*model.Transaction, baseState [][]model.Amount) error {
+	s.rates = rates
+	s.expenses = expenses
+	s.transactions = transactions
+	s.baseState = baseState
+	return nil
+}
+
+func (s *memoryStore) SetDebtMatrix(debtMatrix [][]model.Amount) error {
+	s.debtMatrix = debtMatrix
+	return nil
+}
+
+func (s *memoryStore) SetSettlements(settlements []*model.Transaction) error {
+	s.settlements = settlements
+	return nil
+}
@@ -0,0 +1,63 @@
+package storage
+
+import (
+	"fmt"
+	"github.com/MeysamBavi/group-expense-manager/internal/model"
+	"github.com/MeysamBavi/group-expense-manager/internal/sheet/store"
+	"github.com/MeysamBavi/group-expense-manager/internal/sheet/style"
+	"path/filepath"
+)
+
+// ClosePeriod closes the accounting period of a store kept in a file and creates the store of the next period, in the
+// format chosen by the extension of the file name that it is going to be saved as. The next store has the same
+// members, settings, rates and settlement constraints, no expenses or transactions, and the current debt matrix as its
+// base state, so every member keeps their balance.
+// The end of the closed period and the start of the next one are set to the given time, and each store names the
+// file of the other one in its settings. The source store is changed but not saved; sourceFileName should be the file
+// that it is going to be saved as.
+func ClosePeriod(source Store, sourceFileName, fileName string, theme *style.Theme, t model.Time) (Store, error) {
+	settings := *source.Settings()
+	if settings.PeriodEnd != nil {
+		return nil, fmt.Errorf("the period is already closed at %s and continues in %q", settings.PeriodEnd, settings.NextPeriod)
+	}
+	err := Update(source)
+	if err != nil {
+		return nil, err
+	}
+
+	members := store.NewMemberStore()
+	for _, member := range source.Ledger().Members {
+		m := *member
+		if err := members.AddMember(&m); err != nil {
+			return nil, err
+		}
+	}
+	nextSettings := settings
+	nextSettings.PeriodStart = t
+	nextSettings.PreviousPeriod = filepath.Base(sourceFileName)
+
+	next, err := New(fileName, members, theme, &nextSettings)
+	if err != nil {
+		return nil, err
+	}
+	err = next.ReplaceRecords(source.Rates(), nil, nil, source.DebtMatrix())
+	if err != nil {
+		return nil, err
+	}
+	err = next.SetSettlementConstraints(source.SettlementConstraints())
+	if err != nil {
+		return nil, err
+	}
+	err = Update(next)
+	if err != nil {
+		return nil, err
+	}
+
+	settings.PeriodEnd = t
+	settings.NextPeriod = filepath.Base(fileName)
+	err = source.SetSettings(&settings)
+	if err != nil {
+		return nil, err
+	}
+	return next, nil
+}
@@ -0,0 +1,183 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"github.com/MeysamBavi/group-expense-manager/internal/model"
+	"strings"
+)
+
+// ExpenseFields are the fields of an expense as they are entered by a user, before being parsed.
+// Split and Paid are comma separated lists of members with optional values, like "alice=2,bob,carol=1.5".
+type ExpenseFields struct {
+	Time     string
+	Title    string
+	Payer    string
+	Amount   string
+	Currency string
+	Mode     string
+	Split    string
+	Paid     string
+}
+
+// ParseExpense parses the fields of an expense. The amount is required and should be positive. An empty time means an unspecified time and an empty mode means
+// splitting by weight. A member in the split list without a value gets a share weight of 1; if the split list is
+// empty, the expense is split equally between the active members of the store.
+// The expense is not validated against the members of the store; that is done when it is added.
+func ParseExpense(s Store, fields ExpenseFields) (*model.Expense, error) {
+	if strings.TrimSpace(fields.Title) == "" {
+		return nil, errors.New("title is required")
+	}
+	if strings.TrimSpace(fields.Payer) == "" && strings.TrimSpace(fields.Paid) == "" {
+		return nil, errors.New("either payer or paid is required")
+	}
+	theTime, err := parseTime(fields.Time)
+	if err != nil {
+		return nil, err
+	}
+	amount, err := parseRecordAmount(fields.Amount)
+	if err != nil {
+		return nil, err
+	}
+	mode, err := model.ParseSplitMode(fields.Mode)
+	if err != nil {
+		return nil, err
+	}
+
+	expense := &model.Expense{
+		Title:     strings.TrimSpace(fields.Title),
+		Time:      theTime,
+		PayerName: strings.TrimSpace(fields.Payer),
+		Amount:    amount,
+		Currency:  strings.ToUpper(strings.TrimSpace(fields.Currency)),
+		SplitMode: mode,
+	}
+
+	var names []string
+	shares := make(map[string]*model.Share)
+	getShare := func(name string) *model.Share {
+		key := strings.ToLower(name)
+		if shares[key] == nil {
+			shares[key] = &model.Share{MemberName: name}
+			names = append(names, key)
+		}
+		return shares[key]
+	}
+
+	split := parseMemberValues(fields.Split)
+	if len(split) == 0 {
+		if expense.UsesShareValues() {
+			return nil, fmt.Errorf("split is required in %s mode", mode)
+		}
+		for _, member := range s.Ledger().Members {
+			if !member.Deactivated {
+				split = append(split, memberValue{name: member.Name})
+			}
+		}
+	}
+	for _, mv := range split {
+		share := getShare(mv.name)
+		switch {
+		case mv.value == "" && expense.UsesShareValues():
+			return nil, fmt.Errorf("share of %q is required in %s mode", mv.name, mode)
+		case mv.value == "":
+			share.ShareWeight = 1
+		case expense.UsesShareValues():
+			share.ShareValue, err = model.ParseShareValue(mv.value)
+		default:
+			share.ShareWeight, err = model.ParseShareWeight(mv.value)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, mv := range parseMemberValues(fields.Paid) {
+		getShare(mv.name).Paid, err = model.ParseAmount(mv.value)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, name := range names {
+		expense.Shares = append(expense.Shares, *shares[name])
+	}
+	return expense, nil
+}
+
+// TransactionFields are the fields of a transaction as they are entered by a user, before being parsed.
+type TransactionFields struct {
+	Time     string
+	Receiver string
+	Payer    string
+	Amount   string
+	Currency string
+}
+
+// ParseTransaction parses the fields of a transaction. The amount is required and should be positive. An empty time
+// means an unspecified time.
+// The transaction is not validated against the members of the store; that is done when it is added.
+func ParseTransaction(fields TransactionFields) (*model.Transaction, error) {
+	if strings.TrimSpace(fields.Receiver) == "" || strings.TrimSpace(fields.Payer) == "" {
+		return nil, errors.New("receiver and payer are required")
+	}
+	theTime, err := parseTime(fields.Time)
+	if err != nil {
+		return nil, err
+	}
+	amount, err := parseRecordAmount(fields.Amount)
+	if err != nil {
+		return nil, err
+	}
+	return &model.Transaction{
+		Time:         theTime,
+		ReceiverName: strings.TrimSpace(fields.Receiver),
+		PayerName:    strings.TrimSpace(fields.Payer),
+		Amount:       amount,
+		Currency:     strings.ToUpper(strings.TrimSpace(fields.Currency)),
+	}, nil
+}
+
+// parseRecordAmount parses the amount of an expense or a transaction, which should be positive.
+func parseRecordAmount(value string) (model.Amount, error) {
+	if strings.TrimSpace(value) == "" {
+		return model.Amount{}, errors.New("amount is required")
+	}
+	amount, err := model.ParseAmount(value)
+	if err != nil {
+		return model.Amount{}, err
+	}
+	if !amount.IsPositive() {
+		return model.Amount{}, fmt.Errorf("amount should be positive, but is %s", amount)
+	}
+	return amount, nil
+}
+
+// parseTime parses a time. Empty means an unspecified time, which is kept as nil.
+func parseTime(value string) (model.Time, error) {
+	if strings.TrimSpace(value) == "" {
+		return nil, nil
+	}
+	return model.ParseTime(value)
+}
+
+type memberValue struct {
+	name  string
+	value string
+}
+
+// parseMemberValues parses a comma separated list of members with optional values, like "alice=2,bob,carol=1.5".
+func parseMemberValues(list string) []memberValue {
+	var result []memberValue
+	for _, item := range strings.Split(list, ",") {
+		if strings.TrimSpace(item) == "" {
+			continue
+		}
+		name, value, _ := strings.Cut(item, "=")
+		result = append(result, memberValue{
+			name:  strings.TrimSpace(name),
+			value: strings.TrimSpace(value),
+		})
+	}
+	return result
+}
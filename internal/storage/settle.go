@@ -0,0 +1,53 @@
+package storage
+
+import (
+	"fmt"
+	"github.com/MeysamBavi/group-expense-manager/internal/model"
+)
+
+// SettlementTransactions updates a store and returns its settlements with the given numbers as transactions made at the
+// given time. The settlements are numbered from 1, in the order of Settlements; if numbers is empty, all the
+// settlements are returned. The transactions are not added to the store.
+func SettlementTransactions(s Store, numbers []int, t model.Time) ([]*model.Transaction, error) {
+	err := Update(s)
+	if err != nil {
+		return nil, err
+	}
+	settlements := s.Settlements()
+	if len(numbers) == 0 {
+		for i := range settlements {
+			numbers = append(numbers, i+1)
+		}
+	}
+
+	transactions := make([]*model.Transaction, 0, len(numbers))
+	for _, number := range numbers {
+		if number < 1 || number > len(settlements) {
+			return nil, fmt.Errorf("found no settlement with number %d; there are %d settlements", number, len(settlements))
+		}
+		settlement := settlements[number-1]
+		transactions = append(transactions, &model.Transaction{
+			Time:         t,
+			ReceiverName: settlement.ReceiverName,
+			PayerName:    settlement.PayerName,
+			Amount:       settlement.Amount,
+		})
+	}
+	return transactions, nil
+}
+
+// Settle adds the transactions of SettlementTransactions to a store and updates it again, so the added settlements are
+// no longer suggested.
+func Settle(s Store, numbers []int, t model.Time) ([]*model.Transaction, error) {
+	transactions, err := SettlementTransactions(s, numbers, t)
+	if err != nil {
+		return nil, err
+	}
+	for _, transaction := range transactions {
+		err = s.AddTransaction(transaction)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return transactions, Update(s)
+}
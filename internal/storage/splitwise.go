@@ -0,0 +1,326 @@
+package storage
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"github.com/MeysamBavi/group-expense-manager/internal/log"
+	"github.com/MeysamBavi/group-expense-manager/internal/model"
+	"github.com/MeysamBavi/group-expense-manager/internal/sheet"
+	"github.com/MeysamBavi/group-expense-manager/internal/sheet/store"
+	"github.com/MeysamBavi/group-expense-manager/internal/sheet/style"
+	"github.com/MeysamBavi/group-expense-manager/pkg/ledger"
+	"io"
+	"os"
+	"strings"
+)
+
+// splitwiseColumns are the first columns of a Splitwise CSV export. Every column after them holds the net balance of a
+// member in each row: what they paid minus their share.
+var splitwiseColumns = []string{"date", "description", "category", "cost", "currency"}
+
+const (
+	splitwisePaymentCategory = "payment"
+	splitwiseTotalBalance    = "total balance"
+)
+
+// splitwiseRow is a row of a Splitwise CSV export.
+type splitwiseRow struct {
+	line        int
+	date        string
+	description string
+	category    string
+	cost        string
+	currency    string
+	nets        []string
+}
+
+// ImportSplitwise creates a store for a new group from a Splitwise CSV export, in the format chosen by the extension of
+// the file name that it is going to be saved as. The members are the member columns of the export.
+// Payment rows become transactions and other rows become exact expenses, whose shares are derived from the net balances
+// of the members; the member with the largest net balance is the payer and the rest of the cost is their own share.
+// Rows that do not change any balance are skipped. All the rows should have the same currency, which becomes the base
+// currency. The problems of all the invalid rows are returned together, with their line numbers.
+// If the export has a total balance row, the balances of the debt matrix are checked against it.
+func ImportSplitwise(csvFileName, fileName string, theme *style.Theme) (Store, error) {
+	names, rows, err := readSplitwiseCSV(csvFileName)
+	if err != nil {
+		return nil, err
+	}
+
+	errorOf := func(err error, line int) error {
+		return log.CellErrorOf(err, csvFileName, fmt.Sprintf("line %d", line))
+	}
+
+	var errs log.ErrorList
+	settings := sheet.DefaultSettings()
+	var records []splitwiseRow
+	var totals *splitwiseRow
+	for i, row := range rows {
+		if strings.EqualFold(strings.TrimSpace(row.description), splitwiseTotalBalance) {
+			totals = &rows[i]
+			continue
+		}
+		records = append(records, row)
+		currency := strings.ToUpper(strings.TrimSpace(row.currency))
+		if settings.BaseCurrency == "" {
+			settings.BaseCurrency = currency
+		} else if currency != settings.BaseCurrency {
+			errs.Add(errorOf(fmt.Errorf("currency %q is different from %q; exports with several currencies are not supported",
+				currency, settings.BaseCurrency), row.line))
+		}
+		for _, value := range append([]string{row.cost}, row.nets...) {
+			if digits := fractionDigitsOf(value); digits > settings.FractionDigits {
+				settings.FractionDigits = digits
+			}
+		}
+	}
+	if settings.FractionDigits > sheet.MaxFractionDigits {
+		settings.FractionDigits = sheet.MaxFractionDigits
+	}
+	if errs.Len() > 0 {
+		return nil, errs.Err()
+	}
+
+	members := store.NewMemberStore()
+	for _, name := range names {
+		if err := members.AddMember(&model.Member{Name: name}); err != nil {
+			return nil, log.SheetErrorOf(err, csvFileName)
+		}
+	}
+	s, err := New(fileName, members, theme, settings)
+	if err != nil {
+		return nil, err
+	}
+
+	var expenses []*model.Expense
+	var transactions []*model.Transaction
+	for _, row := range records {
+		expense, transaction, err := parseSplitwiseRow(names, row)
+		switch {
+		case err != nil:
+		case expense != nil:
+			err = store.PrepareExpense(expense, members, s.Rates())
+			expenses = append(expenses, expense)
+		case transaction != nil:
+			err = store.PrepareTransaction(transaction, members, s.Rates())
+			transactions = append(transactions, transaction)
+		}
+		errs.Add(errorOf(err, row.line))
+	}
+	if errs.Len() > 0 {
+		return nil, errs.Err()
+	}
+	err = s.ReplaceRecords(s.Rates(), expenses, transactions, ledger.EmptyMatrix(members.Count()))
+	if err != nil {
+		return nil, err
+	}
+
+	err = Update(s)
+	if err != nil {
+		return nil, err
+	}
+	if totals != nil {
+		err = checkSplitwiseBalances(s, names, totals)
+		if err != nil {
+			return nil, errorOf(err, totals.line)
+		}
+	}
+	return s, nil
+}
+
+// readSplitwiseCSV reads the member names and the non-blank rows of a Splitwise CSV export.
+func readSplitwiseCSV(fileName string) ([]string, []splitwiseRow, error) {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return nil, nil, err
+	}
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	var records [][]string
+	var lines []int
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			_ = file.Close()
+			return nil, nil, err
+		}
+		line, _ := reader.FieldPos(0)
+		records = append(records, record)
+		lines = append(lines, line)
+	}
+	if err := file.Close(); err != nil {
+		return nil, nil, err
+	}
+
+	if len(records) == 0 {
+		return nil, nil, log.SheetErrorOf(errors.New("found no header"), fileName)
+	}
+	header := records[0]
+	if len(header) > 0 {
+		header[0] = strings.TrimPrefix(header[0], "\ufeff")
+	}
+	if len(header) < len(splitwiseColumns)+2 {
+		return nil, nil, log.SheetErrorOf(fmt.Errorf("expected columns %s followed by at least two members",
+			strings.Join(splitwiseColumns, ", ")), fileName)
+	}
+	for i, column := range splitwiseColumns {
+		if !strings.EqualFold(strings.TrimSpace(header[i]), column) {
+			return nil, nil, log.SheetErrorOf(fmt.Errorf("expected column %q, found %q", column, header[i]), fileName)
+		}
+	}
+	var names []string
+	for _, name := range header[len(splitwiseColumns):] {
+		names = append(names, strings.TrimSpace(name))
+	}
+
+	var rows []splitwiseRow
+	var errs log.ErrorList
+	for i, record := range records[1:] {
+		if isBlank(record) {
+			continue
+		}
+		line := lines[i+1]
+		if len(record) != len(header) {
+			errs.Add(log.CellErrorOf(fmt.Errorf("found %d values, expected %d", len(record), len(header)),
+				fileName, fmt.Sprintf("line %d", line)))
+			continue
+		}
+		rows = append(rows, splitwiseRow{
+			line:        line,
+			date:        record[0],
+			description: record[1],
+			category:    record[2],
+			cost:        record[3],
+			currency:    record[4],
+			nets:        record[len(splitwiseColumns):],
+		})
+	}
+	return names, rows, errs.Err()
+}
+
+// parseSplitwiseRow returns the expense or the transaction of a row of a Splitwise export. Both are nil if the row
+// does not change any balance.
+func parseSplitwiseRow(names []string, row splitwiseRow) (*model.Expense, *model.Transaction, error) {
+	theTime, err := parseTime(row.date)
+	if err != nil {
+		return nil, nil, err
+	}
+	nets, err := parseNets(row.nets)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var creditors, debtors []int
+	sum := model.AmountZero()
+	for i, net := range nets {
+		sum = sum.Add(net)
+		if net.IsPositive() {
+			creditors = append(creditors, i)
+		} else if net.IsNegative() {
+			debtors = append(debtors, i)
+		}
+	}
+	if !sum.IsZero() {
+		return nil, nil, fmt.Errorf("net balances sum up to %s instead of zero", sum)
+	}
+	if len(creditors) == 0 {
+		return nil, nil, nil
+	}
+
+	if strings.EqualFold(strings.TrimSpace(row.category), splitwisePaymentCategory) {
+		if len(creditors) != 1 || len(debtors) != 1 {
+			return nil, nil, errors.New("a payment should be between two members")
+		}
+		return nil, &model.Transaction{
+			Time:         theTime,
+			PayerName:    names[creditors[0]],
+			ReceiverName: names[debtors[0]],
+			Amount:       nets[creditors[0]],
+		}, nil
+	}
+
+	cost, err := model.ParseAmount(row.cost)
+	if err != nil {
+		return nil, nil, err
+	}
+	return splitwiseExpense(names, nets, creditors, cost, theTime, strings.TrimSpace(row.description)), nil, nil
+}
+
+// splitwiseExpense returns an exact expense with the given net balances. The creditor with the largest net balance is
+// the payer and the part of the cost that is not owed by anyone else is their own share. If there are several
+// creditors, each of them has paid their net balance, and the payer has paid their own share too.
+func splitwiseExpense(names []string, nets []model.Amount, creditors []int, cost model.Amount, theTime model.Time,
+	title string) *model.Expense {
+	payer := creditors[0]
+	credit := model.AmountZero()
+	for _, i := range creditors {
+		credit = credit.Add(nets[i])
+		if nets[payer].LessThan(nets[i]) {
+			payer = i
+		}
+	}
+	ownShare := cost.Sub(credit)
+	if ownShare.IsNegative() {
+		ownShare = model.AmountZero()
+	}
+
+	expense := &model.Expense{
+		Title:     title,
+		Time:      theTime,
+		PayerName: names[payer],
+		Amount:    credit.Add(ownShare),
+		SplitMode: model.SplitByExact,
+	}
+	for i, net := range nets {
+		share := model.Share{MemberName: names[i]}
+		if net.IsNegative() {
+			share.ShareValue = net.Negative()
+		}
+		if i == payer {
+			share.ShareValue = ownShare
+		}
+		if len(creditors) > 1 && net.IsPositive() {
+			share.Paid = net.Add(share.ShareValue)
+		}
+		expense.Shares = append(expense.Shares, share)
+	}
+	return expense
+}
+
+// checkSplitwiseBalances checks the balances of the debt matrix of a store against the total balance row of an export.
+func checkSplitwiseBalances(s Store, names []string, totals *splitwiseRow) error {
+	expected, err := parseNets(totals.nets)
+	if err != nil {
+		return err
+	}
+	// a positive balance of the debt matrix means debtor, but it means creditor in Splitwise.
+	balances := ledger.ComputeBalances(s.DebtMatrix())
+	for i := range names {
+		if balance := balances[i].Negative(); !balance.Sub(expected[i]).IsZero() {
+			return fmt.Errorf("balance of %q is %s, but it is %s in the export", names[i], balance, expected[i])
+		}
+	}
+	return nil
+}
+
+func parseNets(values []string) ([]model.Amount, error) {
+	nets := make([]model.Amount, len(values))
+	for i, value := range values {
+		net, err := model.ParseAmount(value)
+		if err != nil {
+			return nil, err
+		}
+		nets[i] = net
+	}
+	return nets, nil
+}
+
+// fractionDigitsOf returns the number of digits after the decimal point in an amount.
+func fractionDigitsOf(value string) int {
+	_, fraction, _ := strings.Cut(strings.TrimSpace(value), ".")
+	return len(fraction)
+}
@@ -0,0 +1,743 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/MeysamBavi/group-expense-manager/internal/model"
+	"github.com/MeysamBavi/group-expense-manager/internal/sheet/store"
+	_ "modernc.org/sqlite"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const sqliteDriver = "sqlite"
+
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS settings (
+	key   TEXT PRIMARY KEY,
+	value TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS members (
+	id          INTEGER PRIMARY KEY,
+	name        TEXT NOT NULL UNIQUE COLLATE NOCASE,
+	card_number TEXT NOT NULL,
+	status      TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS rates (
+	id       INTEGER PRIMARY KEY,
+	date     TEXT NOT NULL,
+	currency TEXT NOT NULL,
+	rate     TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS expenses (
+	id       INTEGER PRIMARY KEY,
+	time     TEXT NOT NULL,
+	title    TEXT NOT NULL,
+	payer_id INTEGER,
+	amount   TEXT NOT NULL,
+	currency TEXT NOT NULL,
+	split    TEXT NOT NULL,
+	FOREIGN KEY (payer_id) REFERENCES members (id)
+);
+CREATE TABLE IF NOT EXISTS shares (
+	expense_id INTEGER NOT NULL,
+	member_id  INTEGER NOT NULL,
+	weight     INTEGER NOT NULL,
+	value      TEXT NOT NULL,
+	paid       TEXT NOT NULL,
+	FOREIGN KEY (expense_id) REFERENCES expenses (id) ON DELETE CASCADE,
+	FOREIGN KEY (member_id) REFERENCES members (id)
+);
+CREATE INDEX IF NOT EXISTS shares_expense_id ON shares (expense_id);
+CREATE TABLE IF NOT EXISTS transactions (
+	id          INTEGER PRIMARY KEY,
+	time        TEXT NOT NULL,
+	receiver_id INTEGER NOT NULL,
+	payer_id    INTEGER NOT NULL,
+	amount      TEXT NOT NULL,
+	currency    TEXT NOT NULL,
+	FOREIGN KEY (receiver_id) REFERENCES members (id),
+	FOREIGN KEY (payer_id) REFERENCES members (id)
+);
+CREATE TABLE IF NOT EXISTS base_state (
+	debtor_id   INTEGER NOT NULL,
+	creditor_id INTEGER NOT NULL,
+	amount      TEXT NOT NULL,
+	PRIMARY KEY (debtor_id, creditor_id),
+	FOREIGN KEY (debtor_id) REFERENCES members (id),
+	FOREIGN KEY (creditor_id) REFERENCES members (id)
+);
+CREATE TABLE IF NOT EXISTS debt_matrix (
+	debtor_id   INTEGER NOT NULL,
+	creditor_id INTEGER NOT NULL,
+	amount      TEXT NOT NULL,
+	PRIMARY KEY (debtor_id, creditor_id),
+	FOREIGN KEY (debtor_id) REFERENCES members (id),
+	FOREIGN KEY (creditor_id) REFERENCES members (id)
+);
+CREATE TABLE IF NOT EXISTS settlements (
+	id          INTEGER PRIMARY KEY,
+	receiver_id INTEGER NOT NULL,
+	payer_id    INTEGER NOT NULL,
+	amount      TEXT NOT NULL,
+	FOREIGN KEY (receiver_id) REFERENCES members (id),
+	FOREIGN KEY (payer_id) REFERENCES members (id)
+);
+CREATE TABLE IF NOT EXISTS blocked_pairs (
+	id          INTEGER PRIMARY KEY,
+	payer_id    INTEGER NOT NULL,
+	receiver_id INTEGER NOT NULL,
+	FOREIGN KEY (payer_id) REFERENCES members (id),
+	FOREIGN KEY (receiver_id) REFERENCES members (id)
+);
+CREATE TABLE IF NOT EXISTS preferred_pairs (
+	id          INTEGER PRIMARY KEY,
+	payer_id    INTEGER NOT NULL,
+	receiver_id INTEGER NOT NULL,
+	FOREIGN KEY (payer_id) REFERENCES members (id),
+	FOREIGN KEY (receiver_id) REFERENCES members (id)
+);
+`
+
+const (
+	fractionDigitsKey = "fraction digits"
+	roundingKey       = "rounding"
+	baseCurrencyKey   = "base currency"
+	settlementKey     = "settlement"
+	periodStartKey    = "period start"
+	periodEndKey      = "period end"
+	previousPeriodKey = "previous period"
+	nextPeriodKey     = "next period"
+	treasurerKey      = "treasurer"
+	minimumKey        = "minimum settlement"
+)
+
+// childTables are the tables that reference the members, in an order in which they can be emptied.
+var childTables = []string{"shares", "expenses", "transactions", "base_state", "debt_matrix", "settlements",
+	"blocked_pairs", "preferred_pairs"}
+
+// sqliteStore keeps the data of a group in an SQLite database. The whole database is read when the store is opened.
+// When it is saved to the same database, only the new expenses and transactions are appended and the small tables,
+// like the settings and the settlements, are rewritten; otherwise the database is rewritten in a single transaction.
+type sqliteStore struct {
+	*memoryStore
+	// fileName is the database that the store was read from or last saved to, if it can be updated in place.
+	// savedExpenses and savedTransactions are the number of expenses and transactions kept in it.
+	fileName          string
+	savedExpenses     int
+	savedTransactions int
+}
+
+// openSQLiteStore reads an SQLite database. It does not stop at the first invalid value; all the errors are returned
+// together.
+func openSQLiteStore(fileName string) (*sqliteStore, error) {
+	// sql.Open creates the file if it does not exist
+	if _, err := os.Stat(fileName); err != nil {
+		return nil, err
+	}
+	db, err := openDatabase(fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err := readDocument(db)
+	if closeErr := db.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot read %q: %w", fileName, err)
+	}
+	s, err := decodeDocument(doc)
+	if err != nil {
+		return nil, err
+	}
+	return &sqliteStore{
+		memoryStore:       s,
+		fileName:          fileName,
+		savedExpenses:     len(doc.Expenses),
+		savedTransactions: len(doc.Transactions),
+	}, nil
+}
+
+// openDatabase opens an SQLite database with the foreign keys enforced. The pragma only holds for the connection it is
+// run on, so the database is limited to a single connection.
+func openDatabase(fileName string) (*sql.DB, error) {
+	db, err := sql.Open(sqliteDriver, fileName)
+	if err != nil {
+		return nil, err
+	}
+	db.SetMaxOpenConns(1)
+	if _, err = db.Exec("PRAGMA foreign_keys = ON"); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+func readDocument(db *sql.DB) (*document, error) {
+	doc := &document{}
+
+	err := queryRows(db, "SELECT key, value FROM settings", func(rows *sql.Rows) error {
+		var key, value string
+		if err := rows.Scan(&key, &value); err != nil {
+			return err
+		}
+		var err error
+		switch key {
+		case fractionDigitsKey:
+			doc.Settings.FractionDigits, err = strconv.Atoi(value)
+		case roundingKey:
+			doc.Settings.Rounding = value
+		case baseCurrencyKey:
+			doc.Settings.BaseCurrency = value
+		case settlementKey:
+			doc.Settings.Settlement = value
+		case periodStartKey:
+			doc.Settings.PeriodStart = value
+		case periodEndKey:
+			doc.Settings.PeriodEnd = value
+		case previousPeriodKey:
+			doc.Settings.PreviousPeriod = value
+		case nextPeriodKey:
+			doc.Settings.NextPeriod = value
+		case treasurerKey:
+			constraintsOf(doc).Treasurer = value
+		case minimumKey:
+			constraintsOf(doc).Minimum = value
+		default:
+			err = fmt.Errorf("unknown setting %q", key)
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	memberIndices := make(map[int64]int)
+	err = queryRows(db, "SELECT id, name, card_number, status FROM members ORDER BY id", func(rows *sql.Rows) error {
+		var id int64
+		var m memberRecord
+		if err := rows.Scan(&id, &m.Name, &m.CardNumber, &m.Status); err != nil {
+			return err
+		}
+		memberIndices[id] = len(doc.Members)
+		doc.Members = append(doc.Members, m)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = queryRows(db, "SELECT date, currency, rate FROM rates ORDER BY id", func(rows *sql.Rows) error {
+		var r rateRecord
+		if err := rows.Scan(&r.Date, &r.Currency, &r.Rate); err != nil {
+			return err
+		}
+		doc.Rates = append(doc.Rates, r)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	expenseIndices := make(map[int64]int)
+	err = queryRows(db, `SELECT e.id, e.time, e.title, COALESCE(p.name, ''), e.amount, e.currency, e.split FROM expenses e
+		LEFT JOIN members p ON p.id = e.payer_id ORDER BY e.id`, func(rows *sql.Rows) error {
+		var id int64
+		var e expenseRecord
+		if err := rows.Scan(&id, &e.Time, &e.Title, &e.Payer, &e.Amount, &e.Currency, &e.Split); err != nil {
+			return err
+		}
+		expenseIndices[id] = len(doc.Expenses)
+		doc.Expenses = append(doc.Expenses, e)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = queryRows(db, `SELECT s.expense_id, m.name, s.weight, s.value, s.paid FROM shares s
+		JOIN members m ON m.id = s.member_id ORDER BY s.rowid`, func(rows *sql.Rows) error {
+		var id int64
+		var s shareRecord
+		if err := rows.Scan(&id, &s.Member, &s.Weight, &s.Value, &s.Paid); err != nil {
+			return err
+		}
+		i, ok := expenseIndices[id]
+		if !ok {
+			return fmt.Errorf("found a share of expense %d which does not exist", id)
+		}
+		doc.Expenses[i].Shares = append(doc.Expenses[i].Shares, s)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	doc.Transactions, err = readTransactions(db, `SELECT t.time, r.name, p.name, t.amount, t.currency FROM transactions t
+		JOIN members r ON r.id = t.receiver_id JOIN members p ON p.id = t.payer_id ORDER BY t.id`)
+	if err != nil {
+		return nil, err
+	}
+	doc.Settlements, err = readTransactions(db, `SELECT '', r.name, p.name, s.amount, '' FROM settlements s
+		JOIN members r ON r.id = s.receiver_id JOIN members p ON p.id = s.payer_id ORDER BY s.id`)
+	if err != nil {
+		return nil, err
+	}
+
+	blocked, err := readPairs(db, "blocked_pairs")
+	if err != nil {
+		return nil, err
+	}
+	preferred, err := readPairs(db, "preferred_pairs")
+	if err != nil {
+		return nil, err
+	}
+	if len(blocked) > 0 || len(preferred) > 0 {
+		constraintsOf(doc).Blocked = blocked
+		constraintsOf(doc).Preferred = preferred
+	}
+
+	doc.BaseState, err = readMatrix(db, "base_state", memberIndices)
+	if err != nil {
+		return nil, err
+	}
+	doc.DebtMatrix, err = readMatrix(db, "debt_matrix", memberIndices)
+	if err != nil {
+		return nil, err
+	}
+
+	return doc, nil
+}
+
+// constraintsOf returns the settlement constraints of a document, adding them if it has none.
+func constraintsOf(doc *document) *constraintsRecord {
+	if doc.Constraints == nil {
+		doc.Constraints = &constraintsRecord{}
+	}
+	return doc.Constraints
+}
+
+// readPairs reads the pairs of a payer and a receiver of the settlement constraints.
+func readPairs(db *sql.DB, table string) ([]pairRecord, error) {
+	var result []pairRecord
+	query := "SELECT p.name, r.name FROM " + table +
+		" t JOIN members p ON p.id = t.payer_id JOIN members r ON r.id = t.receiver_id ORDER BY t.id"
+	err := queryRows(db, query, func(rows *sql.Rows) error {
+		var p pairRecord
+		if err := rows.Scan(&p.Payer, &p.Receiver); err != nil {
+			return err
+		}
+		result = append(result, p)
+		return nil
+	})
+	return result, err
+}
+
+func readTransactions(db *sql.DB, query string) ([]transactionRecord, error) {
+	var result []transactionRecord
+	err := queryRows(db, query, func(rows *sql.Rows) error {
+		var t transactionRecord
+		if err := rows.Scan(&t.Time, &t.Receiver, &t.Payer, &t.Amount, &t.Currency); err != nil {
+			return err
+		}
+		result = append(result, t)
+		return nil
+	})
+	return result, err
+}
+
+// readMatrix reads a matrix of debts kept as (debtor, creditor, amount) rows. The missing cells are zero.
+func readMatrix(db *sql.DB, table string, memberIndices map[int64]int) ([][]string, error) {
+	matrix := make([][]string, len(memberIndices))
+	for i := range matrix {
+		matrix[i] = make([]string, len(memberIndices))
+	}
+	err := queryRows(db, "SELECT debtor_id, creditor_id, amount FROM "+table, func(rows *sql.Rows) error {
+		var debtor, creditor int64
+		var amount string
+		if err := rows.Scan(&debtor, &creditor, &amount); err != nil {
+			return err
+		}
+		i, ok := memberIndices[debtor]
+		if !ok {
+			return fmt.Errorf("found no member with id %d in %s", debtor, table)
+		}
+		j, ok := memberIndices[creditor]
+		if !ok {
+			return fmt.Errorf("found no member with id %d in %s", creditor, table)
+		}
+		matrix[i][j] = amount
+		return nil
+	})
+	return matrix, err
+}
+
+// querier is a database or a transaction.
+type querier interface {
+	Query(query string, args ...any) (*sql.Rows, error)
+}
+
+func queryRows(q querier, query string, reader func(rows *sql.Rows) error) error {
+	rows, err := q.Query(query)
+	if err != nil {
+		return err
+	}
+	for rows.Next() {
+		if err := reader(rows); err != nil {
+			_ = rows.Close()
+			return err
+		}
+	}
+	// rows is closed when there are no more rows
+	return rows.Err()
+}
+
+// ReplaceRecords replaces the records of the store. They are not compared with the saved ones, so the next save
+// rewrites the whole database.
+func (s *sqliteStore) ReplaceRecords(rates *store.RateStore, expenses []*model.Expense, transactions []*model.Transaction, baseState [][]model.Amount) error {
+	s.fileName = ""
+	return s.memoryStore.ReplaceRecords(rates, expenses, transactions, baseState)
+}
+
+func (s *sqliteStore) SaveAs(fileName string) error {
+	db, err := openDatabase(fileName)
+	if err != nil {
+		return err
+	}
+	doc := s.document()
+	if fileName == s.fileName {
+		err = updateDocument(db, doc, s.savedExpenses, s.savedTransactions)
+	} else {
+		err = saveDocument(db, doc)
+	}
+	if closeErr := db.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	s.fileName = fileName
+	s.savedExpenses = len(doc.Expenses)
+	s.savedTransactions = len(doc.Transactions)
+	return nil
+}
+
+// saveDocument replaces the content of the database with a document in a single transaction.
+func saveDocument(db *sql.DB, doc *document) error {
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		return err
+	}
+	return inTransaction(db, func(tx *sql.Tx) error {
+		return writeDocument(tx, doc)
+	})
+}
+
+// updateDocument writes a document to the database that it was read from, in a single transaction. The expenses and
+// transactions after the saved ones are appended, and the settings, settlement constraints, debt matrix and settlements
+// are rewritten. If the database does not have the saved number of members, expenses or transactions any more, it is
+// rewritten as a whole.
+func updateDocument(db *sql.DB, doc *document, savedExpenses, savedTransactions int) error {
+	return inTransaction(db, func(tx *sql.Tx) error {
+		var members, expenses, transactions int
+		err := tx.QueryRow("SELECT (SELECT COUNT(*) FROM members), (SELECT COUNT(*) FROM expenses), "+
+			"(SELECT COUNT(*) FROM transactions)").Scan(&members, &expenses, &transactions)
+		if err != nil {
+			return err
+		}
+		if members != len(doc.Members) || expenses != savedExpenses || transactions != savedTransactions {
+			return writeDocument(tx, doc)
+		}
+
+		memberIDs := make(map[string]int64)
+		err = queryRows(tx, "SELECT id, name FROM members", func(rows *sql.Rows) error {
+			var id int64
+			var name string
+			if err := rows.Scan(&id, &name); err != nil {
+				return err
+			}
+			memberIDs[memberKey(name)] = id
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		err = deleteRows(tx, "settings", "debt_matrix", "settlements", "blocked_pairs", "preferred_pairs")
+		if err != nil {
+			return err
+		}
+		if err = writeSettings(tx, doc, memberIDs); err != nil {
+			return err
+		}
+		if err = writeExpenses(tx, doc.Expenses[savedExpenses:], memberIDs); err != nil {
+			return err
+		}
+		if err = writeTransactions(tx, doc.Transactions[savedTransactions:], memberIDs); err != nil {
+			return err
+		}
+		if err = writeSettlements(tx, doc.Settlements, memberIDs); err != nil {
+			return err
+		}
+		return writeMatrix(tx, "debt_matrix", doc.DebtMatrix, memberIDs, doc.Members)
+	})
+}
+
+// inTransaction runs write in a single transaction, which is rolled back if write fails.
+func inTransaction(db *sql.DB, write func(tx *sql.Tx) error) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	if err = write(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+func writeDocument(tx *sql.Tx, doc *document) error {
+	if err := deleteRows(tx, append(childTables, "settings", "members", "rates")...); err != nil {
+		return err
+	}
+
+	memberIDs := make(map[string]int64)
+	const memberQuery = "INSERT INTO members (id, name, card_number, status) VALUES (?, ?, ?, ?)"
+	err := withStatement(tx, memberQuery, func(stmt *sql.Stmt) error {
+		for i, m := range doc.Members {
+			id := int64(i + 1)
+			if _, err := stmt.Exec(id, m.Name, m.CardNumber, m.Status); err != nil {
+				return err
+			}
+			memberIDs[memberKey(m.Name)] = id
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if err = writeSettings(tx, doc, memberIDs); err != nil {
+		return err
+	}
+
+	err = withStatement(tx, "INSERT INTO rates (date, currency, rate) VALUES (?, ?, ?)", func(stmt *sql.Stmt) error {
+		for _, r := range doc.Rates {
+			if _, err := stmt.Exec(r.Date, r.Currency, r.Rate); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if err = writeExpenses(tx, doc.Expenses, memberIDs); err != nil {
+		return err
+	}
+	if err = writeTransactions(tx, doc.Transactions, memberIDs); err != nil {
+		return err
+	}
+	if err = writeSettlements(tx, doc.Settlements, memberIDs); err != nil {
+		return err
+	}
+
+	if err = writeMatrix(tx, "base_state", doc.BaseState, memberIDs, doc.Members); err != nil {
+		return err
+	}
+	return writeMatrix(tx, "debt_matrix", doc.DebtMatrix, memberIDs, doc.Members)
+}
+
+func deleteRows(tx *sql.Tx, tables ...string) error {
+	for _, table := range tables {
+		if _, err := tx.Exec("DELETE FROM " + table); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeSettings writes the settings and the settlement constraints. The tables should be empty.
+func writeSettings(tx *sql.Tx, doc *document, memberIDs map[string]int64) error {
+	settings := [][2]string{
+		{fractionDigitsKey, strconv.Itoa(doc.Settings.FractionDigits)},
+		{roundingKey, doc.Settings.Rounding},
+		{baseCurrencyKey, doc.Settings.BaseCurrency},
+		{settlementKey, doc.Settings.Settlement},
+		{periodStartKey, doc.Settings.PeriodStart},
+		{periodEndKey, doc.Settings.PeriodEnd},
+		{previousPeriodKey, doc.Settings.PreviousPeriod},
+		{nextPeriodKey, doc.Settings.NextPeriod},
+	}
+	if c := doc.Constraints; c != nil {
+		settings = append(settings, [2]string{treasurerKey, c.Treasurer}, [2]string{minimumKey, c.Minimum})
+		if err := writePairs(tx, "blocked_pairs", c.Blocked, memberIDs); err != nil {
+			return err
+		}
+		if err := writePairs(tx, "preferred_pairs", c.Preferred, memberIDs); err != nil {
+			return err
+		}
+	}
+	return withStatement(tx, "INSERT INTO settings (key, value) VALUES (?, ?)", func(stmt *sql.Stmt) error {
+		for _, entry := range settings {
+			if _, err := stmt.Exec(entry[0], entry[1]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// writeExpenses appends expenses and their shares.
+func writeExpenses(tx *sql.Tx, expenses []expenseRecord, memberIDs map[string]int64) error {
+	const expenseQuery = "INSERT INTO expenses (time, title, payer_id, amount, currency, split) VALUES (?, ?, ?, ?, ?, ?)"
+	const shareQuery = "INSERT INTO shares (expense_id, member_id, weight, value, paid) VALUES (?, ?, ?, ?, ?)"
+	return withStatement(tx, expenseQuery, func(expenseStmt *sql.Stmt) error {
+		return withStatement(tx, shareQuery, func(shareStmt *sql.Stmt) error {
+			for _, e := range expenses {
+				var payer sql.NullInt64
+				if e.Payer != "" {
+					id, err := memberID(memberIDs, e.Payer)
+					if err != nil {
+						return err
+					}
+					payer = sql.NullInt64{Int64: id, Valid: true}
+				}
+				result, err := expenseStmt.Exec(e.Time, e.Title, payer, e.Amount, e.Currency, e.Split)
+				if err != nil {
+					return err
+				}
+				id, err := result.LastInsertId()
+				if err != nil {
+					return err
+				}
+				for _, share := range e.Shares {
+					member, err := memberID(memberIDs, share.Member)
+					if err != nil {
+						return err
+					}
+					if _, err = shareStmt.Exec(id, member, share.Weight, share.Value, share.Paid); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		})
+	})
+}
+
+// writeTransactions appends transactions.
+func writeTransactions(tx *sql.Tx, transactions []transactionRecord, memberIDs map[string]int64) error {
+	const query = "INSERT INTO transactions (time, receiver_id, payer_id, amount, currency) VALUES (?, ?, ?, ?, ?)"
+	return withStatement(tx, query, func(stmt *sql.Stmt) error {
+		for _, t := range transactions {
+			receiver, err := memberID(memberIDs, t.Receiver)
+			if err != nil {
+				return err
+			}
+			payer, err := memberID(memberIDs, t.Payer)
+			if err != nil {
+				return err
+			}
+			if _, err = stmt.Exec(t.Time, receiver, payer, t.Amount, t.Currency); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// writeSettlements writes the settlements, which have no time or currency. The table should be empty.
+func writeSettlements(tx *sql.Tx, settlements []transactionRecord, memberIDs map[string]int64) error {
+	const query = "INSERT INTO settlements (receiver_id, payer_id, amount) VALUES (?, ?, ?)"
+	return withStatement(tx, query, func(stmt *sql.Stmt) error {
+		for _, t := range settlements {
+			receiver, err := memberID(memberIDs, t.Receiver)
+			if err != nil {
+				return err
+			}
+			payer, err := memberID(memberIDs, t.Payer)
+			if err != nil {
+				return err
+			}
+			if _, err = stmt.Exec(receiver, payer, t.Amount); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func writePairs(tx *sql.Tx, table string, pairs []pairRecord, memberIDs map[string]int64) error {
+	return withStatement(tx, "INSERT INTO "+table+" (payer_id, receiver_id) VALUES (?, ?)", func(stmt *sql.Stmt) error {
+		for _, p := range pairs {
+			payer, err := memberID(memberIDs, p.Payer)
+			if err != nil {
+				return err
+			}
+			receiver, err := memberID(memberIDs, p.Receiver)
+			if err != nil {
+				return err
+			}
+			if _, err = stmt.Exec(payer, receiver); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// writeMatrix writes the non-zero cells of a matrix of debts as (debtor, creditor, amount) rows. The table should be
+// empty.
+func writeMatrix(tx *sql.Tx, table string, matrix [][]string, memberIDs map[string]int64, members []memberRecord) error {
+	query := "INSERT INTO " + table + " (debtor_id, creditor_id, amount) VALUES (?, ?, ?)"
+	return withStatement(tx, query, func(stmt *sql.Stmt) error {
+		for i := range matrix {
+			for j, amount := range matrix[i] {
+				if amount == "" || amount == "0" {
+					continue
+				}
+				debtor, err := memberID(memberIDs, members[i].Name)
+				if err != nil {
+					return err
+				}
+				creditor, err := memberID(memberIDs, members[j].Name)
+				if err != nil {
+					return err
+				}
+				if _, err = stmt.Exec(debtor, creditor, amount); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// withStatement prepares a statement for the rows written by write and closes it afterwards.
+func withStatement(tx *sql.Tx, query string, write func(stmt *sql.Stmt) error) error {
+	stmt, err := tx.Prepare(query)
+	if err != nil {
+		return err
+	}
+	err = write(stmt)
+	if closeErr := stmt.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+func memberID(memberIDs map[string]int64, name string) (int64, error) {
+	id, ok := memberIDs[memberKey(name)]
+	if !ok {
+		return 0, fmt.Errorf("found no member with name %q", name)
+	}
+	return id, nil
+}
+
+// memberKey returns the key of a member name in the maps of member ids; names are not case-sensitive.
+func memberKey(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
@@ -0,0 +1,228 @@
+// Package storage abstracts the file formats in which the data of a group is kept.
+package storage
+
+import (
+	"fmt"
+	"github.com/MeysamBavi/group-expense-manager/internal/log"
+	"github.com/MeysamBavi/group-expense-manager/internal/model"
+	"github.com/MeysamBavi/group-expense-manager/internal/sheet"
+	"github.com/MeysamBavi/group-expense-manager/internal/sheet/store"
+	"github.com/MeysamBavi/group-expense-manager/internal/sheet/style"
+	"github.com/MeysamBavi/group-expense-manager/pkg/ledger"
+	"os"
+	"path"
+	"strings"
+	"text/tabwriter"
+)
+
+// Store keeps the data of a group. The data is loaded and validated when the store is opened; it is written to a file
+// only by SaveAs.
+type Store interface {
+	// Ledger returns the members, expenses, transactions, base state and settings of the group.
+	Ledger() *ledger.Ledger
+	Settings() *sheet.Settings
+	// SetSettings replaces the settings. The records are not validated again, so only the settings that do not change
+	// them, like the settlement mode and the period, should be changed.
+	SetSettings(settings *sheet.Settings) error
+	// SettlementConstraints returns the constraints of the settlements, or nil if there are none.
+	SettlementConstraints() *ledger.SettlementConstraints
+	// SetSettlementConstraints replaces the constraints of the settlements. The constraints should be valid for the
+	// members of the store; nil means no constraints.
+	SetSettlementConstraints(constraints *ledger.SettlementConstraints) error
+	Rates() *store.RateStore
+	DebtMatrix() [][]model.Amount
+	Settlements() []*model.Transaction
+	// AddExpense validates an expense and appends it to the expenses.
+	AddExpense(expense *model.Expense) error
+	// AddTransaction validates a transaction and appends it to the transactions.
+	AddTransaction(transaction *model.Transaction) error
+	// ReplaceRecords replaces the rates, expenses, transactions and base state. The records should be valid for the
+	// members of the store.
+	ReplaceRecords(rates *store.RateStore, expenses []*model.Expense, transactions []*model.Transaction, baseState [][]model.Amount) error
+	// SetDebtMatrix replaces the computed debt matrix.
+	SetDebtMatrix(debtMatrix [][]model.Amount) error
+	// SetSettlements replaces the computed settlements.
+	SetSettlements(settlements []*model.Transaction) error
+	SaveAs(fileName string) error
+}
+
+// jsonExtension is the extension of the files kept as JSON and sqliteExtensions are the extensions of the files kept as
+// SQLite databases. Files with any other extension are kept as spreadsheets.
+const (
+	jsonExtension   = ".json"
+	sqliteExtension = ".sqlite"
+)
+
+var sqliteExtensions = []string{sqliteExtension, ".sqlite3", ".db"}
+
+// New creates a store for a new group. The format of the store is chosen by the extension of the file name that it is
+// going to be saved as; the theme is only used by spreadsheets.
+func New(fileName string, members *store.MemberStore, theme *style.Theme, settings *sheet.Settings) (Store, error) {
+	switch extensionOf(fileName) {
+	case jsonExtension:
+		return &jsonStore{newMemoryStore(members, settings)}, nil
+	case sqliteExtension:
+		return &sqliteStore{memoryStore: newMemoryStore(members, settings)}, nil
+	default:
+		manager, err := sheet.NewManager(members, theme, settings)
+		if err != nil {
+			return nil, err
+		}
+		return manager, nil
+	}
+}
+
+// Open loads the store kept in a file. The format of the store is chosen by the extension of the file name.
+func Open(fileName string) (Store, error) {
+	switch extensionOf(fileName) {
+	case jsonExtension:
+		return openJSONStore(fileName)
+	case sqliteExtension:
+		return openSQLiteStore(fileName)
+	default:
+		manager, err := sheet.LoadManager(fileName)
+		if err != nil {
+			return nil, err
+		}
+		return manager, nil
+	}
+}
+
+// Copy creates a store with the data of another store, including the settlement constraints, in the format chosen by
+// the extension of the file name that it is going to be saved as. The debt matrix and the settlements are calculated
+// again; the theme is only used by spreadsheets.
+func Copy(source Store, fileName string, theme *style.Theme) (Store, error) {
+	l := source.Ledger()
+	members := store.NewMemberStore()
+	for _, member := range l.Members {
+		m := *member
+		if err := members.AddMember(&m); err != nil {
+			return nil, err
+		}
+	}
+	settings := *source.Settings()
+
+	target, err := New(fileName, members, theme, &settings)
+	if err != nil {
+		return nil, err
+	}
+	err = target.ReplaceRecords(source.Rates(), l.Expenses, l.Transactions, l.BaseState)
+	if err != nil {
+		return nil, err
+	}
+	err = target.SetSettlementConstraints(source.SettlementConstraints())
+	if err != nil {
+		return nil, err
+	}
+	err = Update(target)
+	if err != nil {
+		return nil, err
+	}
+	return target, nil
+}
+
+// Update calculates the debt matrix and the settlements of a store. The settlements are chosen by the settlement mode of
+// the settings and satisfy the settlement constraints.
+func Update(s Store) error {
+	l := s.Ledger()
+	debtMatrix, err := ledger.ComputeDebtMatrix(l)
+	if err != nil {
+		return err
+	}
+	err = s.SetDebtMatrix(debtMatrix)
+	if err != nil {
+		return err
+	}
+	settlements, err := ledger.ComputeConstrainedSettlements(l.Members, debtMatrix, s.Settings().Settlement,
+		s.SettlementConstraints())
+	if err != nil {
+		return err
+	}
+	return s.SetSettlements(settlements)
+}
+
+// PrintData prints the members, expenses, transactions and base state of a store. If summarize is true, only the
+// number of expenses and transactions is printed.
+func PrintData(s Store, summarize bool) {
+	l := s.Ledger()
+	var sb strings.Builder
+
+	fmt.Fprintln(&sb, "Members:")
+	for _, member := range l.Members {
+		fmt.Fprintf(&sb, "  %s\t%s\t%s\n", member.Name, member.CardNumber, member.Status())
+	}
+
+	if summarize {
+		fmt.Fprintf(&sb, "Expenses: %d\n", len(l.Expenses))
+		fmt.Fprintf(&sb, "Transactions: %d\n", len(l.Transactions))
+	} else {
+		fmt.Fprintln(&sb, "Expenses:")
+		for _, expense := range l.Expenses {
+			payer := expense.PayerName
+			if expense.HasPaidPortions() {
+				var payers []string
+				for _, share := range expense.Shares {
+					if !share.Paid.IsZero() {
+						payers = append(payers, fmt.Sprintf("%s=%s", share.MemberName, share.Paid))
+					}
+				}
+				payer = strings.Join(payers, ",")
+			}
+			var split []string
+			for _, share := range expense.Shares {
+				switch {
+				case expense.UsesShareValues() && !share.ShareValue.IsZero():
+					split = append(split, fmt.Sprintf("%s=%s", share.MemberName, share.ShareValue))
+				case !expense.UsesShareValues() && share.ShareWeight != 0:
+					split = append(split, fmt.Sprintf("%s=%d", share.MemberName, share.ShareWeight))
+				}
+			}
+			fmt.Fprintf(&sb, "  %s\t%s\t%s\tpaid by %s\t%s split %s\n", timeString(expense.Time), expense.Title,
+				amountString(expense.Amount, expense.Currency), payer, expense.SplitMode, strings.Join(split, ","))
+		}
+
+		fmt.Fprintln(&sb, "Transactions:")
+		for _, transaction := range l.Transactions {
+			fmt.Fprintf(&sb, "  %s\t%s paid %s\t%s\n", timeString(transaction.Time), transaction.PayerName,
+				transaction.ReceiverName, amountString(transaction.Amount, transaction.Currency))
+		}
+	}
+
+	fmt.Fprintln(&sb, "Base State:")
+	for i, row := range l.BaseState {
+		for j, amount := range row {
+			if i != j && !amount.IsZero() {
+				fmt.Fprintf(&sb, "  %s owes %s\t%s\n", l.Members[i].Name, l.Members[j].Name, amount)
+			}
+		}
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, err := w.Write([]byte(sb.String()))
+	if err == nil {
+		err = w.Flush()
+	}
+	if err != nil {
+		log.Error(err)
+	}
+}
+
+// amountString returns an amount followed by its currency, if any.
+func amountString(amount model.Amount, currency string) string {
+	if currency == "" {
+		return amount.String()
+	}
+	return amount.String() + " " + currency
+}
+
+// extensionOf returns the extension of a file name that decides its format. All the SQLite extensions are reported as
+// sqliteExtension.
+func extensionOf(fileName string) string {
+	ext := strings.ToLower(path.Ext(fileName))
+	for _, e := range sqliteExtensions {
+		if ext == e {
+			return sqliteExtension
+		}
+	}
+	return ext
+}
@@ -0,0 +1,295 @@
+package storage_test
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/MeysamBavi/group-expense-manager/internal/log"
+	"github.com/MeysamBavi/group-expense-manager/internal/model"
+	"github.com/MeysamBavi/group-expense-manager/internal/sheet/style"
+	"github.com/MeysamBavi/group-expense-manager/internal/storage"
+	"github.com/MeysamBavi/group-expense-manager/internal/storage/storagetest"
+	"github.com/MeysamBavi/group-expense-manager/pkg/ledger"
+	assert2 "github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// settlementsOf returns the settlements of a store as strings, like "bob -> alice: 15".
+func settlementsOf(s storage.Store) []string {
+	var result []string
+	for _, settlement := range s.Settlements() {
+		result = append(result, fmt.Sprintf("%s -> %s: %s", settlement.PayerName, settlement.ReceiverName, settlement.Amount))
+	}
+	return result
+}
+
+func TestStores(t *testing.T) {
+	assert := assert2.New(t)
+
+	dir := t.TempDir()
+	xlsxStore := storagetest.Scenario(t, filepath.Join(dir, "group.xlsx"))
+	jsonStore := storagetest.Scenario(t, filepath.Join(dir, "group.json"))
+	sqliteStore := storagetest.Scenario(t, filepath.Join(dir, "group.sqlite"))
+
+	assert.Len(xlsxStore.Ledger().Expenses, 3)
+	assert.Len(jsonStore.Ledger().Expenses, 3)
+	assert.Len(jsonStore.Ledger().Transactions, 1)
+	assert.Equal(xlsxStore.DebtMatrix(), jsonStore.DebtMatrix())
+	assert.Equal(settlementsOf(xlsxStore), settlementsOf(jsonStore))
+	assert.NotEmpty(settlementsOf(jsonStore))
+	assert.Equal(xlsxStore.DebtMatrix(), sqliteStore.DebtMatrix())
+	assert.Equal(settlementsOf(xlsxStore), settlementsOf(sqliteStore))
+}
+
+func TestSQLiteStore(t *testing.T) {
+	assert := assert2.New(t)
+
+	fileName := filepath.Join(t.TempDir(), "group.sqlite")
+	s := storagetest.Scenario(t, fileName)
+
+	db, err := sql.Open("sqlite", fileName)
+	if !assert.NoError(err) {
+		return
+	}
+	defer func() {
+		assert.NoError(db.Close())
+	}()
+	_, err = db.Exec("PRAGMA foreign_keys = ON")
+	assert.NoError(err)
+	_, err = db.Exec("INSERT INTO transactions (time, receiver_id, payer_id, amount, currency) VALUES ('', 99, 1, '1', '')")
+	assert.Error(err, "the receiver is not a member")
+	// the saved rows are not written again, so the change is kept
+	_, err = db.Exec("UPDATE expenses SET title = 'late dinner' WHERE title = 'dinner'")
+	assert.NoError(err)
+
+	assert.NoError(s.AddExpense(&model.Expense{
+		Title:     "lunch",
+		PayerName: "bob",
+		Amount:    storagetest.Amount(t, "20"),
+		Shares:    []model.Share{{MemberName: "alice", ShareWeight: 1}, {MemberName: "bob", ShareWeight: 1}},
+	}))
+	assert.NoError(s.AddTransaction(&model.Transaction{ReceiverName: "bob", PayerName: "alice", Amount: storagetest.Amount(t, "10")}))
+	s = storagetest.Reopen(t, s, fileName)
+
+	expenses := s.Ledger().Expenses
+	if assert.Len(expenses, 4) {
+		assert.Equal("late dinner", expenses[0].Title)
+		assert.Equal("lunch", expenses[3].Title)
+		assert.Equal("bob", expenses[3].PayerName)
+	}
+	assert.Len(s.Ledger().Transactions, 2)
+	assert.NotEmpty(settlementsOf(s))
+}
+
+func TestCopy(t *testing.T) {
+	assert := assert2.New(t)
+
+	dir := t.TempDir()
+	source := storagetest.Scenario(t, filepath.Join(dir, "group.xlsx"))
+	constraints := &ledger.SettlementConstraints{
+		Treasurer:     "alice",
+		Blocked:       []ledger.BlockedPair{{PayerName: "bob", ReceiverName: "carol"}},
+		Preferred:     []ledger.PreferredPair{{PayerName: "dave", ReceiverName: "carol"}},
+		MinimumAmount: storagetest.Amount(t, "5"),
+	}
+	assert.NoError(source.SetSettlementConstraints(constraints))
+	assert.NoError(storage.Update(source))
+
+	fileName := filepath.Join(dir, "migrated.db")
+	migrated, err := storage.Copy(source, fileName, style.BlueTheme())
+	assert.NoError(err)
+	assert.NoError(migrated.SaveAs(fileName))
+	migrated, err = storage.Open(fileName)
+	assert.NoError(err)
+	assert.Len(migrated.Ledger().Expenses, 3)
+	assert.Equal(constraints, migrated.SettlementConstraints())
+
+	fileName = filepath.Join(dir, "migrated.json")
+	converted, err := storage.Copy(migrated, fileName, style.BlueTheme())
+	assert.NoError(err)
+	converted = storagetest.Reopen(t, converted, fileName)
+	assert.Equal(constraints, converted.SettlementConstraints())
+
+	fileName = filepath.Join(dir, "exported.xlsx")
+	exported, err := storage.Copy(migrated, fileName, style.BlueTheme())
+	assert.NoError(err)
+	assert.NoError(exported.SaveAs(fileName))
+	exported, err = storage.Open(fileName)
+	assert.NoError(err)
+	assert.NoError(storage.Update(exported))
+	assert.Equal(constraints, exported.SettlementConstraints())
+
+	assert.Equal(settlementsOf(source), settlementsOf(migrated))
+	assert.Equal(settlementsOf(source), settlementsOf(converted))
+	assert.Equal(settlementsOf(source), settlementsOf(exported))
+}
+
+func TestExportCSV(t *testing.T) {
+	assert := assert2.New(t)
+
+	dir := t.TempDir()
+	s := storagetest.Scenario(t, filepath.Join(dir, "group.json"))
+
+	fileNames, err := storage.ExportCSV(s, filepath.Join(dir, "csv"))
+	assert.NoError(err)
+	assert.Len(fileNames, 6)
+
+	content, err := os.ReadFile(filepath.Join(dir, "csv", "expenses.csv"))
+	assert.NoError(err)
+	assert.Equal(`expense_id,time,title,payer,total_amount,currency,split,member,share_weight,share_value,paid,share_amount
+1,,dinner,alice,100,,weight,alice,1,,0,33.34
+1,,dinner,alice,100,,weight,bob,1,,0,33.33
+1,,dinner,alice,100,,weight,carol,1,,0,33.33
+2,,taxi,,30.5,,exact,bob,0,10,20,10
+2,,taxi,,30.5,,exact,dave,0,20.5,10.5,20.5
+3,,tickets,carol,45,,percent,alice,0,50,0,22.5
+3,,tickets,carol,45,,percent,dave,0,50,0,22.5
+`, string(content))
+
+	content, err = os.ReadFile(filepath.Join(dir, "csv", "settlements.csv"))
+	assert.NoError(err)
+	assert.Contains(string(content), "payer,receiver,amount\n")
+}
+
+func TestParseRecords(t *testing.T) {
+	assert := assert2.New(t)
+
+	s := storagetest.Scenario(t, filepath.Join(t.TempDir(), "group.json"))
+	expense, err := storage.ParseExpense(s, storage.ExpenseFields{Title: "lunch", Payer: "alice", Amount: "12.5"})
+	if assert.NoError(err) {
+		assert.Equal("12.5", expense.Amount.String())
+		assert.Len(expense.Shares, 4)
+	}
+	for amount, message := range map[string]string{
+		"":   "amount is required",
+		"0":  "amount should be positive, but is 0",
+		"-5": "amount should be positive, but is -5",
+	} {
+		_, err = storage.ParseExpense(s, storage.ExpenseFields{Title: "lunch", Payer: "alice", Amount: amount})
+		assert.EqualError(err, message)
+		_, err = storage.ParseTransaction(storage.TransactionFields{Receiver: "alice", Payer: "bob", Amount: amount})
+		assert.EqualError(err, message)
+	}
+}
+
+func TestImportExpensesCSV(t *testing.T) {
+	assert := assert2.New(t)
+
+	dir := t.TempDir()
+	s := storagetest.Scenario(t, filepath.Join(dir, "group.xlsx"))
+
+	csvFileName := filepath.Join(dir, "expenses.csv")
+	err := os.WriteFile(csvFileName, []byte(`Title,Payer,Amount,Split,Mode
+lunch,bob,60,"alice=2,bob",
+
+snacks,zed,10,,
+,alice,10,,
+gift,dave,40,"alice=25,carol=75",percent
+tip,alice, ,,
+`), 0644)
+	assert.NoError(err)
+
+	count, err := storage.ImportExpensesCSV(s, csvFileName)
+	assert.Equal(2, count)
+	var validationErr *log.ValidationError
+	assert.ErrorAs(err, &validationErr)
+	assert.Len(validationErr.Errors, 3)
+	assert.Contains(err.Error(), `line 4: found no member with name "zed"`)
+	assert.Contains(err.Error(), "line 5: title is required")
+	assert.Contains(err.Error(), "line 7: amount is required")
+
+	expenses := s.Ledger().Expenses
+	assert.Len(expenses, 5)
+	assert.Equal("lunch", expenses[3].Title)
+	assert.Equal(2, expenses[3].Shares[0].ShareWeight)
+	assert.Equal(model.SplitByPercentage, expenses[4].SplitMode)
+}
+
+func TestImportSplitwise(t *testing.T) {
+	assert := assert2.New(t)
+
+	dir := t.TempDir()
+	csvFileName := filepath.Join(dir, "export.csv")
+	err := os.WriteFile(csvFileName, []byte(`Date,Description,Category,Cost,Currency,alice,bob,carol
+
+2023-01-05,Dinner,Dining out,90.00,USD,60.00,-30.00,-30.00
+2023-01-06,bob paid alice,Payment,20.00,USD,-20.00,20.00,0.00
+2023-01-09,Hotel,Lodging,100.00,USD,10.00,10.00,-20.00
+
+2023-01-31,Total balance, , ,USD,50.00,0.00,-50.00
+`), 0644)
+	assert.NoError(err)
+
+	s, err := storage.ImportSplitwise(csvFileName, filepath.Join(dir, "group.xlsx"), style.BlueTheme())
+	assert.NoError(err)
+
+	l := s.Ledger()
+	assert.Len(l.Members, 3)
+	assert.Len(l.Expenses, 2)
+	assert.Equal("USD", s.Settings().BaseCurrency)
+	assert.Equal(2, s.Settings().FractionDigits)
+	assert.Equal("bob", l.Transactions[0].PayerName)
+	assert.Equal("alice", l.Transactions[0].ReceiverName)
+	assert.Equal("90", l.Expenses[1].Shares[0].Paid.String())
+	assert.Equal([]string{"carol -> alice: 50"}, settlementsOf(s))
+}
+
+func TestSettle(t *testing.T) {
+	assert := assert2.New(t)
+
+	s := storagetest.Scenario(t, filepath.Join(t.TempDir(), "group.json"))
+	settlements := settlementsOf(s)
+	assert.Len(settlements, 3)
+
+	_, err := storage.Settle(s, []int{4}, nil)
+	assert.Error(err)
+
+	transactions, err := storage.SettlementTransactions(s, []int{2}, nil)
+	assert.NoError(err)
+	assert.Len(s.Ledger().Transactions, 1)
+	assert.Equal(settlements[1], fmt.Sprintf("%s -> %s: %s", transactions[0].PayerName, transactions[0].ReceiverName, transactions[0].Amount))
+
+	transactions, err = storage.Settle(s, []int{2}, nil)
+	assert.NoError(err)
+	assert.Len(transactions, 1)
+	assert.Len(s.Ledger().Transactions, 2)
+	assert.Len(s.Settlements(), 2)
+
+	_, err = storage.Settle(s, nil, nil)
+	assert.NoError(err)
+	assert.Empty(s.Settlements())
+}
+
+func TestClosePeriod(t *testing.T) {
+	assert := assert2.New(t)
+
+	dir := t.TempDir()
+	s := storagetest.Scenario(t, filepath.Join(dir, "group.json"))
+	constraints := &ledger.SettlementConstraints{Treasurer: "carol", MinimumAmount: model.AmountZero()}
+	assert.NoError(s.SetSettlementConstraints(constraints))
+	closedAt := model.TimeOfGregorian(time.Date(2024, time.January, 1, 0, 0, 0, 0, time.Local))
+
+	next, err := storage.ClosePeriod(s, filepath.Join(dir, "group.json"), filepath.Join(dir, "next.xlsx"),
+		style.BlueTheme(), closedAt)
+	if !assert.NoError(err) {
+		return
+	}
+	assert.Empty(next.Ledger().Expenses)
+	assert.Empty(next.Ledger().Transactions)
+	assert.Equal(ledger.ComputeBalances(s.DebtMatrix()), ledger.ComputeBalances(next.DebtMatrix()))
+	assert.Equal(settlementsOf(s), settlementsOf(next))
+
+	assert.Equal(closedAt, s.Settings().PeriodEnd)
+	assert.Equal("next.xlsx", s.Settings().NextPeriod)
+	assert.Equal(closedAt, next.Settings().PeriodStart)
+	assert.Nil(next.Settings().PeriodEnd)
+	assert.Equal("group.json", next.Settings().PreviousPeriod)
+	assert.Equal(2, next.Settings().FractionDigits)
+	assert.Equal(constraints, next.SettlementConstraints())
+
+	_, err = storage.ClosePeriod(s, filepath.Join(dir, "group.json"), filepath.Join(dir, "other.xlsx"),
+		style.BlueTheme(), closedAt)
+	assert.Error(err)
+}
@@ -0,0 +1,110 @@
+// Package storagetest creates the groups that the tests of GEM work with.
+package storagetest
+
+import (
+	"github.com/MeysamBavi/group-expense-manager/internal/model"
+	"github.com/MeysamBavi/group-expense-manager/internal/sheet"
+	"github.com/MeysamBavi/group-expense-manager/internal/sheet/store"
+	"github.com/MeysamBavi/group-expense-manager/internal/sheet/style"
+	"github.com/MeysamBavi/group-expense-manager/internal/storage"
+	"testing"
+)
+
+// Members returns a member store with the given members.
+func Members(t testing.TB, names ...string) *store.MemberStore {
+	t.Helper()
+	members := store.NewMemberStore()
+	for _, name := range names {
+		if err := members.AddMember(&model.Member{Name: name}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return members
+}
+
+// Amount parses an amount.
+func Amount(t testing.TB, value string) model.Amount {
+	t.Helper()
+	amount, err := model.ParseAmount(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return amount
+}
+
+// Reopen updates a store, saves it and returns the store opened again from the file.
+func Reopen(t testing.TB, s storage.Store, fileName string) storage.Store {
+	t.Helper()
+	if err := storage.Update(s); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SaveAs(fileName); err != nil {
+		t.Fatal(err)
+	}
+	s, err := storage.Open(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = storage.Update(s); err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+// Scenario creates a group of alice, bob, carol and dave in the format chosen by the extension of the file name, with
+// expenses split by weight, exact amounts and percentages, one of them paid by two members, and a transaction.
+// The amounts have two fraction digits in USD. The group is saved and returned opened again from the file.
+func Scenario(t testing.TB, fileName string) storage.Store {
+	t.Helper()
+	settings := sheet.DefaultSettings()
+	settings.FractionDigits = 2
+	settings.BaseCurrency = "USD"
+
+	s, err := storage.New(fileName, Members(t, "alice", "bob", "carol", "dave"), style.BlueTheme(), settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s = Reopen(t, s, fileName)
+
+	expenses := []*model.Expense{
+		{
+			Title:     "dinner",
+			PayerName: "alice",
+			Amount:    Amount(t, "100"),
+			Shares: []model.Share{
+				{MemberName: "alice", ShareWeight: 1},
+				{MemberName: "bob", ShareWeight: 1},
+				{MemberName: "carol", ShareWeight: 1},
+			},
+		},
+		{
+			Title:     "taxi",
+			Amount:    Amount(t, "30.5"),
+			SplitMode: model.SplitByExact,
+			Shares: []model.Share{
+				{MemberName: "bob", ShareValue: Amount(t, "10"), Paid: Amount(t, "20")},
+				{MemberName: "dave", ShareValue: Amount(t, "20.5"), Paid: Amount(t, "10.5")},
+			},
+		},
+		{
+			Title:     "tickets",
+			PayerName: "carol",
+			Amount:    Amount(t, "45"),
+			SplitMode: model.SplitByPercentage,
+			Shares: []model.Share{
+				{MemberName: "alice", ShareValue: Amount(t, "50")},
+				{MemberName: "dave", ShareValue: Amount(t, "50")},
+			},
+		},
+	}
+	for _, expense := range expenses {
+		if err = s.AddExpense(expense); err != nil {
+			t.Fatal(err)
+		}
+	}
+	err = s.AddTransaction(&model.Transaction{ReceiverName: "alice", PayerName: "bob", Amount: Amount(t, "15")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return Reopen(t, s, fileName)
+}
@@ -0,0 +1,176 @@
+package ledger
+
+import (
+	"fmt"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Amount is an exact decimal amount of money. The zero value is zero.
+type Amount struct {
+	r *big.Rat
+}
+
+// AmountOf returns a whole amount.
+func AmountOf(a int64) Amount {
+	return Amount{big.NewRat(a, 1)}
+}
+
+// AmountZero returns the zero amount.
+func AmountZero() Amount {
+	return Amount{zeroRat()}
+}
+
+func (a Amount) IsZero() bool {
+	return a.r == nil || a.r.Sign() == 0
+}
+
+func (a Amount) IsNegative() bool {
+	return a.r != nil && a.r.Sign() == -1
+}
+
+func (a Amount) IsPositive() bool {
+	return a.r != nil && a.r.Sign() == 1
+}
+
+func zeroRat() *big.Rat {
+	return new(big.Rat)
+}
+
+func (a Amount) Negative() Amount {
+	if a.IsZero() {
+		return Amount{zeroRat()}
+	}
+	b := new(big.Rat).Set(a.r)
+	return Amount{b.Neg(b)}
+}
+
+// Abs returns the amount without its sign.
+func (a Amount) Abs() Amount {
+	if a.IsNegative() {
+		return a.Negative()
+	}
+	return a.Add(AmountZero())
+}
+
+func (a Amount) Add(b Amount) Amount {
+	switch {
+	case a.IsZero() && b.IsZero():
+		return Amount{zeroRat()}
+	case a.IsZero():
+		return Amount{zeroRat().Set(b.r)}
+	case b.IsZero():
+		return Amount{zeroRat().Set(a.r)}
+	default:
+		return Amount{zeroRat().Add(a.r, b.r)}
+	}
+}
+
+func (a Amount) Sub(b Amount) Amount {
+	return a.Add(b.Negative())
+}
+
+func (a Amount) Multiply(b int) Amount {
+	if a.IsZero() {
+		return Amount{zeroRat()}
+	}
+	r := zeroRat().SetInt64(int64(b))
+	return Amount{r.Mul(r, a.r)}
+}
+
+func (a Amount) Divide(c int) Amount {
+	if a.IsZero() {
+		return Amount{zeroRat()}
+	}
+	r := zeroRat().SetInt64(int64(c))
+	return Amount{r.Quo(a.r, r)}
+}
+
+func (a Amount) MultiplyBy(b Amount) Amount {
+	if a.IsZero() || b.IsZero() {
+		return Amount{zeroRat()}
+	}
+	return Amount{zeroRat().Mul(a.r, b.r)}
+}
+
+// Round returns the amount rounded to the given number of fraction digits, the same way Format rounds it.
+func (a Amount) Round(fractionDigits int) Amount {
+	if a.IsZero() {
+		return Amount{zeroRat()}
+	}
+	r, _ := zeroRat().SetString(a.r.FloatString(fractionDigits))
+	return Amount{r}
+}
+
+func (a Amount) LessThan(b Amount) bool {
+	switch {
+	case a.IsZero() && b.IsZero():
+		return false
+	case a.IsZero():
+		return b.r.Sign() == 1
+	case b.IsZero():
+		return a.r.Sign() == -1
+	default:
+		return a.r.Cmp(b.r) == -1
+	}
+}
+
+func (a Amount) ToNumeral() int64 {
+	if a.IsZero() {
+		return 0
+	}
+	return (big.NewInt(0).Set(a.r.Num())).Div(a.r.Num(), a.r.Denom()).Int64()
+}
+
+// ToFloat returns the amount rounded to the given number of fraction digits.
+func (a Amount) ToFloat(fractionDigits int) float64 {
+	if a.IsZero() {
+		return 0
+	}
+	f, _ := strconv.ParseFloat(a.r.FloatString(fractionDigits), 64)
+	return f
+}
+
+// Format returns the amount rounded to the given number of fraction digits, as a decimal string.
+func (a Amount) Format(fractionDigits int) string {
+	if a.IsZero() {
+		return zeroRat().FloatString(fractionDigits)
+	}
+	return a.r.FloatString(fractionDigits)
+}
+
+// String returns the amount as a decimal string, with no trailing zeros in the fraction part.
+// Non-terminating fractions are rounded to maxStringFractionDigits digits.
+func (a Amount) String() string {
+	if a.IsZero() {
+		return "0"
+	}
+	if a.r.IsInt() {
+		return a.r.FloatString(0)
+	}
+
+	return strings.TrimRight(strings.TrimRight(a.r.FloatString(maxStringFractionDigits), "0"), ".")
+}
+
+const maxStringFractionDigits = 8
+
+var amountExp = regexp.MustCompile(`^[-+]?(\d+(\.\d*)?|\.\d+)$`)
+
+// ParseAmount parses a decimal amount. Commas are ignored and an empty string is considered zero.
+func ParseAmount(a string) (Amount, error) {
+	a = strings.TrimSpace(a)
+	a = strings.ReplaceAll(a, ",", "")
+	if a == "" {
+		return Amount{zeroRat()}, nil
+	}
+	if !amountExp.MatchString(a) {
+		return Amount{zeroRat()}, fmt.Errorf("cannot parse %q as amount", a)
+	}
+	r, ok := zeroRat().SetString(a)
+	if !ok {
+		return Amount{zeroRat()}, fmt.Errorf("cannot parse %q as amount", a)
+	}
+	return Amount{r}, nil
+}
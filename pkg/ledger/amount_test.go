@@ -0,0 +1,155 @@
+package ledger_test
+
+import (
+	"github.com/MeysamBavi/group-expense-manager/pkg/ledger"
+	assert2 "github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestAmount_Add(t *testing.T) {
+	assert := assert2.New(t)
+	assert.Equal(int64(34), ledger.AmountOf(17).Add(ledger.AmountOf(17)).ToNumeral())
+	assert.Equal(int64(50), ledger.AmountOf(34).Add(ledger.AmountOf(16)).ToNumeral())
+	assert.Equal(int64(34), ledger.AmountOf(-1).Add(ledger.AmountOf(35)).ToNumeral())
+	assert.Equal(int64(0), ledger.AmountOf(0).Add(ledger.AmountOf(0)).ToNumeral())
+	assert.Equal(int64(103833), ledger.AmountOf(0).Add(ledger.AmountOf(103833)).Add(ledger.AmountZero()).ToNumeral())
+	assert.Equal(int64(207666), ledger.AmountZero().Add(ledger.AmountOf(103833)).Add(ledger.AmountOf(103833)).ToNumeral())
+	assert.Equal(int64(1), ledger.AmountOf(270000).
+		Add(ledger.AmountOf(-270000)).Add(ledger.AmountOf(1)).ToNumeral())
+}
+
+func TestAmount_Sub(t *testing.T) {
+	assert := assert2.New(t)
+	assert.Equal(int64(43), ledger.AmountOf(80).Sub(ledger.AmountOf(37)).ToNumeral())
+	assert.Equal(int64(80), ledger.AmountOf(80).Sub(ledger.AmountOf(0)).ToNumeral())
+	assert.Equal(int64(80), ledger.AmountOf(0).Sub(ledger.AmountOf(-80)).ToNumeral())
+	assert.Equal(int64(120), ledger.AmountOf(60).Sub(ledger.AmountOf(-60)).ToNumeral())
+}
+
+func TestAmount_Multiply(t *testing.T) {
+	assert := assert2.New(t)
+	assert.Equal(int64(64), ledger.AmountOf(16).Multiply(4).ToNumeral())
+	assert.Equal(int64(-64), ledger.AmountOf(-16).Multiply(4).ToNumeral())
+	assert.Equal(int64(0), ledger.AmountOf(-16).Multiply(0).ToNumeral())
+	assert.Equal(int64(429), ledger.AmountOf(143).Multiply(3).ToNumeral())
+	assert.Equal(int64(-6400), ledger.AmountOf(800).Multiply(-8).ToNumeral())
+	assert.Equal(int64(6400), ledger.AmountOf(-800).Multiply(-8).ToNumeral())
+}
+
+func TestAmount_Divide(t *testing.T) {
+	assert := assert2.New(t)
+	assert.Equal(int64(4), ledger.AmountOf(102).Divide(25).ToNumeral())
+	assert.Equal(int64(4), ledger.AmountOf(100).Divide(25).ToNumeral())
+	assert.Equal(int64(3), ledger.AmountOf(100).Divide(26).ToNumeral())
+	assert.Equal(int64(0), ledger.AmountOf(0).Divide(26).ToNumeral())
+	assert.Equal(int64(4), ledger.AmountOf(-8).Divide(-2).ToNumeral())
+	assert.Equal(int64(2), ledger.AmountOf(-8).Divide(-3).ToNumeral())
+}
+
+func TestAmount_Negative(t *testing.T) {
+	assert := assert2.New(t)
+	assert.Equal(int64(-1), ledger.AmountOf(1).Negative().ToNumeral())
+	assert.Equal(int64(0), ledger.AmountOf(0).Negative().ToNumeral())
+	assert.Equal(int64(1), ledger.AmountOf(-1).Negative().ToNumeral())
+	assert.Equal(int64(-(1 << 31)), ledger.AmountOf(1<<31).Negative().ToNumeral())
+}
+
+func TestAmount_IsNegative(t *testing.T) {
+	assert := assert2.New(t)
+	assert.Equal(true, ledger.AmountOf(-2).IsNegative())
+	assert.Equal(true, ledger.AmountOf(-1).IsNegative())
+	assert.Equal(false, ledger.AmountOf(0).IsNegative())
+	assert.Equal(false, ledger.AmountOf(1).IsNegative())
+	assert.Equal(false, ledger.AmountOf(1<<32).IsNegative())
+	assert.Equal(true, ledger.AmountOf(-(1 << 32)).IsNegative())
+}
+
+func TestAmount_LessThan(t *testing.T) {
+	assert := assert2.New(t)
+	assert.Equal(true, ledger.AmountOf(64).LessThan(ledger.AmountOf(66)))
+	assert.Equal(true, ledger.AmountOf(0).LessThan(ledger.AmountOf(66)))
+	assert.Equal(true, ledger.AmountOf(-1).LessThan(ledger.AmountOf(0)))
+	assert.Equal(true, ledger.AmountOf(-11).LessThan(ledger.AmountOf(-5)))
+	assert.Equal(true, ledger.AmountOf(-11).LessThan(ledger.AmountOf(-10)))
+	assert.Equal(false, ledger.AmountOf(-11).LessThan(ledger.AmountOf(-11)))
+	assert.Equal(false, ledger.AmountOf(64).LessThan(ledger.AmountOf(-10)))
+	assert.Equal(false, ledger.AmountOf(64).LessThan(ledger.AmountOf(63)))
+	assert.Equal(false, ledger.AmountOf(64).LessThan(ledger.AmountOf(64)))
+}
+
+func TestAmount_Immutability(t *testing.T) {
+	assert := assert2.New(t)
+
+	a := ledger.AmountOf(3)
+	b := ledger.AmountOf(13)
+
+	a.Add(b)
+	assert.Equal(int64(3), a.ToNumeral())
+	assert.Equal(int64(13), b.ToNumeral())
+
+	a.Sub(b)
+	assert.Equal(int64(3), a.ToNumeral())
+	assert.Equal(int64(13), b.ToNumeral())
+
+	a.Multiply(3)
+	assert.Equal(int64(3), a.ToNumeral())
+	assert.Equal(int64(13), b.ToNumeral())
+
+	a.Divide(18)
+	assert.Equal(int64(3), a.ToNumeral())
+	assert.Equal(int64(13), b.ToNumeral())
+
+	a.Divide(2)
+	assert.Equal(int64(3), a.ToNumeral())
+	assert.Equal(int64(13), b.ToNumeral())
+
+	c := ledger.AmountOf(19).Divide(2)
+	assert.Equal(int64(9), c.ToNumeral())
+	assert.Equal(int64(9), c.ToNumeral())
+
+	a.Negative()
+	assert.Equal(int64(3), a.ToNumeral())
+	assert.Equal(int64(13), b.ToNumeral())
+}
+
+func TestParseAmount(t *testing.T) {
+	assert := assert2.New(t)
+
+	valid := map[string]string{
+		"":            "0",
+		" 1,200,000 ": "1200000",
+		"12.5":        "12.5",
+		"-0.75":       "-0.75",
+		".5":          "0.5",
+		"3.":          "3",
+		"+10.010":     "10.01",
+	}
+	for input, expected := range valid {
+		amount, err := ledger.ParseAmount(input)
+		assert.NoError(err, input)
+		assert.Equal(expected, amount.String(), input)
+	}
+
+	for _, input := range []string{"abc", "1/3", "1e3", "1.2.3", "--1", "."} {
+		_, err := ledger.ParseAmount(input)
+		assert.Error(err, input)
+	}
+}
+
+func TestAmount_Format(t *testing.T) {
+	assert := assert2.New(t)
+	assert.Equal("0.00", ledger.AmountZero().Format(2))
+	assert.Equal("33.33", ledger.AmountOf(100).Divide(3).Format(2))
+	assert.Equal("66.67", ledger.AmountOf(200).Divide(3).Format(2))
+	assert.Equal("67", ledger.AmountOf(200).Divide(3).Format(0))
+	assert.Equal("-2.5", ledger.AmountOf(-5).Divide(2).Format(1))
+	assert.Equal("33.33333333", ledger.AmountOf(100).Divide(3).String())
+}
+
+func TestAmount_ToFloat(t *testing.T) {
+	assert := assert2.New(t)
+	assert.Equal(0.0, ledger.AmountZero().ToFloat(2))
+	assert.Equal(33.33, ledger.AmountOf(100).Divide(3).ToFloat(2))
+	assert.Equal(67.0, ledger.AmountOf(200).Divide(3).ToFloat(0))
+	assert.Equal(-1200000.0, ledger.AmountOf(-1200000).ToFloat(0))
+}
@@ -0,0 +1,438 @@
+package ledger
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// SettlementConstraints restricts the settlements of a group.
+type SettlementConstraints struct {
+	// Treasurer is the name of the member who collects the money of all the debtors and pays all the creditors. Empty
+	// means there is no treasurer.
+	Treasurer string
+	// Blocked are the pairs of members where the payer never pays the receiver.
+	Blocked []BlockedPair
+	// Preferred are the pairs of members where the payer pays the receiver before anyone else.
+	Preferred []PreferredPair
+	// MinimumAmount is the smallest amount of a settlement. Zero means there is no minimum.
+	MinimumAmount Amount
+}
+
+// BlockedPair is a payer who never pays a receiver.
+type BlockedPair struct {
+	PayerName    string
+	ReceiverName string
+}
+
+// PreferredPair is a payer who would rather pay a receiver than the other members.
+type PreferredPair struct {
+	PayerName    string
+	ReceiverName string
+}
+
+// IsEmpty reports whether the constraints do not restrict any settlement.
+func (c *SettlementConstraints) IsEmpty() bool {
+	return c == nil || c.Treasurer == "" && len(c.Blocked) == 0 && len(c.Preferred) == 0 && !c.MinimumAmount.IsPositive()
+}
+
+// ComputeConstrainedSettlements is like ComputeSettlementsByMode, but the settlements satisfy the constraints:
+//   - With a treasurer, every debtor pays the treasurer and the treasurer pays every creditor, whatever the mode.
+//   - With the greedy or the optimal mode, a debtor first pays their preferred receivers as much as they are owed, in
+//     the order of the pairs, and the rest of the balances is settled by the mode. If the preferences leave no way to
+//     satisfy the blocked pairs, they are not followed.
+//   - If a settlement of the greedy or the optimal mode is between a blocked pair, the debtors are matched with the
+//     creditors they are allowed to pay instead. The pairwise and simplified modes only pay the debts of the matrix, so
+//     a blocked debt can not be moved and the preferred pairs are not used.
+//   - No settlement is less than the minimum amount. If a settlement of the greedy or the optimal mode is smaller, the
+//     small debts are merged into larger settlements or sent through other members instead, and the preferred pairs
+//     are not used. The settlements of a treasurer and of the pairwise and simplified modes can not be changed, so a
+//     small settlement is an error.
+//
+// If the constraints can not be satisfied, the error explains why.
+func ComputeConstrainedSettlements(members []*Member, debtMatrix [][]Amount, mode SettlementMode,
+	constraints *SettlementConstraints) ([]*Transaction, error) {
+	if constraints.IsEmpty() {
+		return ComputeSettlementsByMode(members, debtMatrix, mode)
+	}
+
+	l := &Ledger{Members: members}
+	blocked := make(map[[2]int]bool)
+	for _, pair := range constraints.Blocked {
+		payerIndex, err := l.requireIndexOf(pair.PayerName)
+		if err != nil {
+			return nil, err
+		}
+		receiverIndex, err := l.requireIndexOf(pair.ReceiverName)
+		if err != nil {
+			return nil, err
+		}
+		blocked[[2]int{payerIndex, receiverIndex}] = true
+	}
+	isBlocked := func(settlement *Transaction) bool {
+		return blocked[[2]int{l.IndexOf(settlement.PayerName), l.IndexOf(settlement.ReceiverName)}]
+	}
+	allowed := func(payer, receiver string) bool {
+		return !blocked[[2]int{l.IndexOf(payer), l.IndexOf(receiver)}]
+	}
+	var preferred []PreferredPair
+	for _, pair := range constraints.Preferred {
+		payerIndex, err := l.requireIndexOf(pair.PayerName)
+		if err != nil {
+			return nil, err
+		}
+		receiverIndex, err := l.requireIndexOf(pair.ReceiverName)
+		if err != nil {
+			return nil, err
+		}
+		preferred = append(preferred, PreferredPair{PayerName: members[payerIndex].Name, ReceiverName: members[receiverIndex].Name})
+	}
+
+	var settlements []*Transaction
+	if constraints.Treasurer != "" {
+		treasurerIndex, err := l.requireIndexOf(constraints.Treasurer)
+		if err != nil {
+			return nil, err
+		}
+		settlements = treasurerSettlements(nonZeroBalances(members, debtMatrix), members[treasurerIndex].Name)
+		for _, settlement := range settlements {
+			if isBlocked(settlement) {
+				return nil, fmt.Errorf("%s should pay %s %s through the treasurer, but %s never pays %s",
+					settlement.PayerName, settlement.ReceiverName, settlement.Amount, settlement.PayerName, settlement.ReceiverName)
+			}
+		}
+	} else if mode == PairwiseSettlement || mode == SimplifiedSettlement {
+		var err error
+		settlements, err = ComputeSettlementsByMode(members, debtMatrix, mode)
+		if err != nil {
+			return nil, err
+		}
+		for _, settlement := range settlements {
+			if isBlocked(settlement) {
+				return nil, fmt.Errorf("%s owes %s %s, but %s never pays %s; the %s settlement mode only pays the existing debts",
+					settlement.PayerName, settlement.ReceiverName, settlement.Amount, settlement.PayerName, settlement.ReceiverName, mode)
+			}
+		}
+	} else {
+		balances := nonZeroBalances(members, debtMatrix)
+		var err error
+		settlements, err = modeSettlements(balances, preferred, mode, isBlocked, allowed)
+		if err != nil && len(preferred) > 0 {
+			settlements, err = modeSettlements(balances, nil, mode, isBlocked, allowed)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if minimum := constraints.MinimumAmount; minimum.IsPositive() {
+		for _, settlement := range settlements {
+			if !settlement.Amount.LessThan(minimum) {
+				continue
+			}
+			message := fmt.Sprintf("%s should pay %s %s, which is less than the minimum settlement of %s",
+				settlement.PayerName, settlement.ReceiverName, settlement.Amount, minimum)
+			switch {
+			case constraints.Treasurer != "":
+				return nil, fmt.Errorf("%s; every debtor pays the treasurer all their debt", message)
+			case mode == PairwiseSettlement || mode == SimplifiedSettlement:
+				return nil, fmt.Errorf("%s; the %s settlement mode only pays the existing debts", message, mode)
+			}
+			var ok bool
+			settlements, ok = chainSettlements(nonZeroBalances(members, debtMatrix), minimum, allowed)
+			if !ok {
+				return nil, fmt.Errorf("%s, and no other settlements are all at least the minimum", message)
+			}
+			break
+		}
+	}
+
+	sort.SliceStable(settlements, func(i, j int) bool {
+		return settlements[i].Amount.LessThan(settlements[j].Amount)
+	})
+	return settlements, nil
+}
+
+// modeSettlements settles the balances by the greedy or the optimal mode, after paying the preferred receivers. If a
+// settlement is between a blocked pair, the balances that are left after the preferred receivers are settled by
+// allowedSettlements instead.
+func modeSettlements(balances []balance, preferred []PreferredPair, mode SettlementMode, isBlocked func(*Transaction) bool,
+	allowed func(payer, receiver string) bool) ([]*Transaction, error) {
+	settlements, balances := preferredSettlements(balances, preferred, allowed)
+	var rest []*Transaction
+	if mode == OptimalSettlement {
+		rest = optimalSettlements(balances)
+	} else {
+		rest = greedySettlements(balances)
+	}
+	for _, settlement := range rest {
+		if isBlocked(settlement) {
+			var err error
+			rest, err = allowedSettlements(balances, allowed)
+			if err != nil {
+				return nil, err
+			}
+			break
+		}
+	}
+	return append(settlements, rest...), nil
+}
+
+// preferredSettlements pays the preferred receivers of each debtor as much as possible, in the order of the pairs. The
+// pairs that are not allowed are skipped. It returns the settlements and the non-zero balances that are left; the
+// balances are not changed.
+func preferredSettlements(balances []balance, preferred []PreferredPair, allowed func(payer, receiver string) bool) ([]*Transaction, []balance) {
+	left := make([]balance, len(balances))
+	copy(left, balances)
+	indexOf := func(name string) int {
+		for i, b := range left {
+			if b.name == name {
+				return i
+			}
+		}
+		return -1
+	}
+
+	settlements := make([]*Transaction, 0)
+	for _, pair := range preferred {
+		payer, receiver := indexOf(pair.PayerName), indexOf(pair.ReceiverName)
+		if payer == -1 || receiver == -1 || !allowed(pair.PayerName, pair.ReceiverName) ||
+			!left[payer].amount.IsPositive() || !left[receiver].amount.IsNegative() {
+			continue
+		}
+		amount := left[payer].amount
+		if credit := left[receiver].amount.Negative(); credit.LessThan(amount) {
+			amount = credit
+		}
+		settlements = append(settlements, &Transaction{ReceiverName: pair.ReceiverName, PayerName: pair.PayerName, Amount: amount})
+		left[payer].amount = left[payer].amount.Sub(amount)
+		left[receiver].amount = left[receiver].amount.Add(amount)
+	}
+
+	nonZero := make([]balance, 0, len(left))
+	for _, b := range left {
+		if !b.amount.IsZero() {
+			nonZero = append(nonZero, b)
+		}
+	}
+	return settlements, nonZero
+}
+
+// chainSearchLimit is the maximum number of states that chainSettlements visits before it gives up.
+const chainSearchLimit = 1 << 20
+
+// chainSettlements settles the balances by a chain of members, where every member pays the next one all that they and
+// the members before them owe, so that each settlement is at least the minimum and only between the allowed pairs. A
+// member with a small balance can then pass on a larger amount. When the members before the next one are settled up,
+// the next one starts a new chain. A chain of n members needs at most n-1 settlements, like GreedySettlement.
+// It searches the orders of the members, trying the largest balances first, and reports false if there is no such
+// order or the search takes too long.
+func chainSettlements(balances []balance, minimum Amount, allowed func(payer, receiver string) bool) ([]*Transaction, bool) {
+	members := make([]balance, len(balances))
+	copy(members, balances)
+	sort.SliceStable(members, func(i, j int) bool {
+		return members[j].amount.Abs().LessThan(members[i].amount.Abs())
+	})
+	if len(members) > 63 {
+		return nil, false
+	}
+
+	// a state is the members in the chain so far and the last one of them; the sum of their balances is positive if
+	// the last one should pay the next one. The last member does not matter when the sum is zero.
+	type state struct {
+		chain uint64
+		last  int
+	}
+	visited := make(map[state]bool)
+	order := make([]int, 0, len(members))
+	var search func(chain uint64, last int, sum Amount) bool
+	search = func(chain uint64, last int, sum Amount) bool {
+		if len(order) == len(members) {
+			return true
+		}
+		if sum.IsZero() {
+			last = -1
+		}
+		s := state{chain: chain, last: last}
+		if visited[s] || len(visited) >= chainSearchLimit {
+			return false
+		}
+		visited[s] = true
+
+		for next, member := range members {
+			if chain&(1<<next) != 0 {
+				continue
+			}
+			switch {
+			case last == -1:
+			case sum.Abs().LessThan(minimum):
+				return false
+			case sum.IsPositive() && !allowed(members[last].name, member.name):
+				continue
+			case sum.IsNegative() && !allowed(member.name, members[last].name):
+				continue
+			}
+			order = append(order, next)
+			if search(chain|1<<next, next, sum.Add(member.amount)) {
+				return true
+			}
+			order = order[:len(order)-1]
+		}
+		return false
+	}
+	if !search(0, -1, AmountZero()) {
+		return nil, false
+	}
+
+	settlements := make([]*Transaction, 0)
+	sum := AmountZero()
+	for i, next := range order {
+		if i > 0 {
+			last := members[order[i-1]].name
+			switch {
+			case sum.IsPositive():
+				settlements = append(settlements, &Transaction{ReceiverName: members[next].name, PayerName: last, Amount: sum})
+			case sum.IsNegative():
+				settlements = append(settlements, &Transaction{ReceiverName: last, PayerName: members[next].name, Amount: sum.Negative()})
+			}
+		}
+		sum = sum.Add(members[next].amount)
+	}
+	return settlements, true
+}
+
+// treasurerSettlements settles the balances through the treasurer: every debtor pays the treasurer and the treasurer
+// pays every creditor.
+func treasurerSettlements(balances []balance, treasurer string) []*Transaction {
+	settlements := make([]*Transaction, 0)
+	for _, b := range balances {
+		switch {
+		case b.name == treasurer:
+		case b.amount.IsPositive():
+			settlements = append(settlements, &Transaction{ReceiverName: treasurer, PayerName: b.name, Amount: b.amount})
+		default:
+			settlements = append(settlements, &Transaction{ReceiverName: b.name, PayerName: treasurer, Amount: b.amount.Negative()})
+		}
+	}
+	return settlements
+}
+
+// allowedSettlements settles the balances so that every debtor only pays the creditors that they are allowed to pay. It
+// finds a maximum flow from the debtors to the creditors, trying the largest debtors and creditors first. If the debtors
+// can not pay all their debts, the error names a group of debtors who owe more than the creditors they are allowed to
+// pay are owed.
+func allowedSettlements(balances []balance, allowed func(payer, receiver string) bool) ([]*Transaction, error) {
+	sort.SliceStable(balances, func(i, j int) bool {
+		return balances[j].amount.LessThan(balances[i].amount)
+	})
+	var debtors, creditors []balance
+	total := AmountZero()
+	for _, b := range balances {
+		if b.amount.IsPositive() {
+			debtors = append(debtors, b)
+			total = total.Add(b.amount)
+		}
+	}
+	for i := len(balances) - 1; i >= 0; i-- {
+		if balances[i].amount.IsNegative() {
+			creditors = append(creditors, balance{name: balances[i].name, amount: balances[i].amount.Negative()})
+		}
+	}
+
+	// the nodes are the source, the debtors, the creditors and the sink. residual[i][j] is how much more can flow from
+	// node i to node j; a debtor can pay an allowed creditor up to the total debt.
+	source, sink := 0, len(debtors)+len(creditors)+1
+	debtorNode := func(i int) int { return 1 + i }
+	creditorNode := func(i int) int { return 1 + len(debtors) + i }
+	residual := EmptyMatrix(sink + 1)
+	for i, debtor := range debtors {
+		residual[source][debtorNode(i)] = debtor.amount
+		for j, creditor := range creditors {
+			if allowed(debtor.name, creditor.name) {
+				residual[debtorNode(i)][creditorNode(j)] = total
+			}
+		}
+	}
+	for j, creditor := range creditors {
+		residual[creditorNode(j)][sink] = creditor.amount
+	}
+
+	flow := AmountZero()
+	for {
+		// breadth-first search for the shortest path with some room left
+		parents := make([]int, len(residual))
+		for i := range parents {
+			parents[i] = -1
+		}
+		parents[source] = source
+		queue := []int{source}
+		for len(queue) > 0 && parents[sink] == -1 {
+			node := queue[0]
+			queue = queue[1:]
+			for next := range residual {
+				if parents[next] == -1 && residual[node][next].IsPositive() {
+					parents[next] = node
+					queue = append(queue, next)
+				}
+			}
+		}
+
+		if parents[sink] == -1 {
+			if flow.LessThan(total) {
+				return nil, unpaidDebtsError(debtors, creditors, func(i int) bool { return parents[debtorNode(i)] != -1 },
+					func(j int) bool { return parents[creditorNode(j)] != -1 })
+			}
+			break
+		}
+
+		room := total
+		for node := sink; node != source; node = parents[node] {
+			if residual[parents[node]][node].LessThan(room) {
+				room = residual[parents[node]][node]
+			}
+		}
+		for node := sink; node != source; node = parents[node] {
+			residual[parents[node]][node] = residual[parents[node]][node].Sub(room)
+			residual[node][parents[node]] = residual[node][parents[node]].Add(room)
+		}
+		flow = flow.Add(room)
+	}
+
+	settlements := make([]*Transaction, 0)
+	for i, debtor := range debtors {
+		for j, creditor := range creditors {
+			if !allowed(debtor.name, creditor.name) {
+				continue
+			}
+			if paid := total.Sub(residual[debtorNode(i)][creditorNode(j)]); paid.IsPositive() {
+				settlements = append(settlements, &Transaction{ReceiverName: creditor.name, PayerName: debtor.name, Amount: paid})
+			}
+		}
+	}
+	return settlements, nil
+}
+
+// unpaidDebtsError explains why the debtors can not pay their debts: the debtors who can still pay more owe more than
+// all the creditors they are allowed to pay.
+func unpaidDebtsError(debtors, creditors []balance, isDebtorLeft, isCreditorReachable func(int) bool) error {
+	var debtorNames, creditorNames []string
+	debts, credits := AmountZero(), AmountZero()
+	for i, debtor := range debtors {
+		if isDebtorLeft(i) {
+			debtorNames = append(debtorNames, debtor.name)
+			debts = debts.Add(debtor.amount)
+		}
+	}
+	for j, creditor := range creditors {
+		if isCreditorReachable(j) {
+			creditorNames = append(creditorNames, creditor.name)
+			credits = credits.Add(creditor.amount)
+		}
+	}
+	if len(creditorNames) == 0 {
+		return fmt.Errorf("%s should pay %s, but can not pay any of the members who should receive money",
+			strings.Join(debtorNames, ", "), debts)
+	}
+	return fmt.Errorf("%s should pay %s, but can only pay %s, who should receive %s",
+		strings.Join(debtorNames, ", "), debts, strings.Join(creditorNames, ", "), credits)
+}
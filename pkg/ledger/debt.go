@@ -0,0 +1,176 @@
+package ledger
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ComputeDebtMatrix calculates the debts of the members after the base state, the expenses and the transactions of the
+// ledger. The returned matrix is netted: for every pair of members, at most one of them owes the other.
+func ComputeDebtMatrix(l *Ledger) ([][]Amount, error) {
+	debtMatrix := EmptyMatrix(len(l.Members))
+	if l.BaseState != nil {
+		if len(l.BaseState) != len(l.Members) {
+			return nil, fmt.Errorf("base state has %d rows but there are %d members", len(l.BaseState), len(l.Members))
+		}
+		debtMatrix = CopyMatrix(l.BaseState)
+	}
+
+	for _, expense := range l.Expenses {
+		if err := l.addExpense(debtMatrix, expense); err != nil {
+			return nil, fmt.Errorf("expense %q: %w", expense.Title, err)
+		}
+	}
+
+	for _, transaction := range l.Transactions {
+		receiverIndex, payerIndex, amount, err := l.transactionDebt(transaction)
+		if err != nil {
+			return nil, err
+		}
+		debtMatrix[payerIndex][receiverIndex] =
+			debtMatrix[payerIndex][receiverIndex].Sub(amount)
+	}
+
+	netDebts(debtMatrix)
+	return debtMatrix, nil
+}
+
+// ComputeShareAmounts returns the amount of each share of an expense in the base currency, rounded by the rounding
+// policy of the ledger. The amounts are in the order of the shares and sum up to the converted amount of the expense.
+// If the payer has no share in the expense, PayerAbsorbs rounds the amount like LargestRemainder.
+func ComputeShareAmounts(l *Ledger, expense *Expense) ([]Amount, error) {
+	payerIndex := expensePayerIndex(expense)
+	amount, err := l.convert(expense.Amount, expense.Currency, expense.Time)
+	if err != nil {
+		return nil, err
+	}
+	rounding := l.Rounding
+	if rounding == "" {
+		rounding = LargestRemainder
+	}
+	return amount.SplitByAmounts(expense.Proportions(), payerIndex, l.FractionDigits, rounding), nil
+}
+
+func (l *Ledger) addExpense(debtMatrix [][]Amount, expense *Expense) error {
+	memberIndices, debts, err := l.expenseDebts(expense)
+	if err != nil {
+		return err
+	}
+	for i := range debts {
+		for j, debt := range debts[i] {
+			debtMatrix[memberIndices[i]][memberIndices[j]] =
+				debtMatrix[memberIndices[i]][memberIndices[j]].Add(debt)
+		}
+	}
+	return nil
+}
+
+// expenseDebts returns the indices of the members of the shares of an expense and what they owe each other because of
+// it: debts[i][j] is what the member of share i owes the member of share j, in the base currency. If the payer has no
+// share in the expense, they are added after the members of the shares.
+func (l *Ledger) expenseDebts(expense *Expense) ([]int, [][]Amount, error) {
+	memberIndices := make([]int, len(expense.Shares))
+	for i, share := range expense.Shares {
+		memberIndex, err := l.requireIndexOf(share.MemberName)
+		if err != nil {
+			return nil, nil, err
+		}
+		memberIndices[i] = memberIndex
+	}
+
+	shareAmounts, err := ComputeShareAmounts(l, expense)
+	if err != nil {
+		return nil, nil, err
+	}
+	payerIndex := expensePayerIndex(expense)
+	paidPortions := expense.PaidPortions(payerIndex)
+	if payerIndex == -1 && !expense.HasPaidPortions() {
+		payerMemberIndex, err := l.requireIndexOf(expense.PayerName)
+		if err != nil {
+			return nil, nil, err
+		}
+		memberIndices = append(memberIndices, payerMemberIndex)
+		shareAmounts = append(shareAmounts, AmountZero())
+		paidPortions = append(paidPortions, expense.Amount)
+	}
+	debts := make([][]Amount, len(memberIndices))
+	for i := range debts {
+		// each payer is credited proportionally to their paid portion
+		debts[i] = shareAmounts[i].SplitByAmounts(paidPortions, -1, l.FractionDigits, LargestRemainder)
+	}
+	return memberIndices, debts, nil
+}
+
+// transactionDebt returns the indices of the receiver and the payer of a transaction and its amount in the base
+// currency, which is subtracted from the debt of the payer to the receiver.
+func (l *Ledger) transactionDebt(transaction *Transaction) (int, int, Amount, error) {
+	receiverIndex, err := l.requireIndexOf(transaction.ReceiverName)
+	if err != nil {
+		return -1, -1, Amount{}, err
+	}
+	payerIndex, err := l.requireIndexOf(transaction.PayerName)
+	if err != nil {
+		return -1, -1, Amount{}, err
+	}
+	amount, err := l.convert(transaction.Amount, transaction.Currency, transaction.Time)
+	if err != nil {
+		return -1, -1, Amount{}, err
+	}
+	return receiverIndex, payerIndex, amount.Round(l.FractionDigits), nil
+}
+
+// expensePayerIndex returns the index of the share of the payer of an expense. If the expense has multiple payers and
+// no payer is specified, the one with the largest paid portion is returned.
+func expensePayerIndex(expense *Expense) int {
+	if expense.PayerName != "" || !expense.HasPaidPortions() {
+		return shareIndexOf(expense, expense.PayerName)
+	}
+	payerIndex := 0
+	for i, share := range expense.Shares {
+		if expense.Shares[payerIndex].Paid.LessThan(share.Paid) {
+			payerIndex = i
+		}
+	}
+	return payerIndex
+}
+
+func shareIndexOf(expense *Expense, name string) int {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for i, share := range expense.Shares {
+		if strings.ToLower(strings.TrimSpace(share.MemberName)) == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// netDebts cancels out the mutual debts of every pair of members.
+func netDebts(debtMatrix [][]Amount) {
+	for r := range debtMatrix {
+		for c := r; c < len(debtMatrix); c++ {
+			if debtMatrix[r][c].LessThan(debtMatrix[c][r]) {
+				debtMatrix[c][r] = debtMatrix[c][r].Sub(debtMatrix[r][c])
+				debtMatrix[r][c] = AmountZero()
+			} else {
+				debtMatrix[r][c] = debtMatrix[r][c].Sub(debtMatrix[c][r])
+				debtMatrix[c][r] = AmountZero()
+			}
+		}
+	}
+}
+
+// ComputeBalances returns the net balance of each member based on a debt matrix. Positive means debtor.
+func ComputeBalances(debtMatrix [][]Amount) []Amount {
+	balances := make([]Amount, len(debtMatrix))
+	for memberIndex := range balances {
+		gives, receives := AmountZero(), AmountZero()
+		for i := range debtMatrix {
+			receives = receives.Add(debtMatrix[i][memberIndex])
+		}
+		for i := range debtMatrix {
+			gives = gives.Add(debtMatrix[memberIndex][i])
+		}
+		balances[memberIndex] = gives.Sub(receives)
+	}
+	return balances
+}
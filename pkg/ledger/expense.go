@@ -0,0 +1,196 @@
+package ledger
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// SplitMode specifies how the amount of an expense is divided between the members.
+type SplitMode string
+
+const (
+	// SplitByWeight divides the amount proportionally to the share weights.
+	SplitByWeight SplitMode = "weight"
+	// SplitByExact gives each member the exact amount stated in their share. The shares should sum up to the amount.
+	SplitByExact SplitMode = "exact"
+	// SplitByPercentage gives each member a percentage of the amount. The percentages should sum up to 100.
+	SplitByPercentage SplitMode = "percent"
+)
+
+var SplitModes = []SplitMode{SplitByWeight, SplitByExact, SplitByPercentage}
+
+// ParseSplitMode parses a split mode. Empty means SplitByWeight.
+func ParseSplitMode(value string) (SplitMode, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if value == "" {
+		return SplitByWeight, nil
+	}
+	for _, mode := range SplitModes {
+		if value == string(mode) {
+			return mode, nil
+		}
+	}
+	return "", fmt.Errorf("invalid split mode %q", value)
+}
+
+// Share is the part of a member in an expense: how much of it they owe and how much of it they have paid.
+type Share struct {
+	MemberName  string
+	ShareWeight int
+	// ShareValue is the exact amount or the percentage of the share, used instead of ShareWeight
+	// when the split mode of the expense is SplitByExact or SplitByPercentage.
+	ShareValue Amount
+	// Paid is the portion of the amount that the member has paid, if the expense has multiple payers.
+	Paid Amount
+}
+
+// Expense is an amount paid by one or more members for some members of the group.
+// Shares lists the members who owe a part of the amount; the members who are not listed owe nothing. A single payer
+// does not need a share of their own.
+type Expense struct {
+	Title string
+	Time  Time
+	// PayerName is the member who paid the whole amount. If the paid portions of the shares are set, it can be empty
+	// or one of the payers.
+	PayerName string
+	Amount    Amount
+	// Currency of the amount. Empty means the base currency of the group.
+	Currency  string
+	SplitMode SplitMode
+	Shares    []Share
+}
+
+func (e *Expense) SumOfWeights() int {
+	sum := 0
+	for _, share := range e.Shares {
+		sum += share.ShareWeight
+	}
+	return sum
+}
+
+// SumOfValues returns the sum of share values.
+func (e *Expense) SumOfValues() Amount {
+	sum := AmountZero()
+	for _, share := range e.Shares {
+		sum = sum.Add(share.ShareValue)
+	}
+	return sum
+}
+
+// UsesShareValues reports whether the shares are expressed by ShareValue instead of ShareWeight.
+func (e *Expense) UsesShareValues() bool {
+	return e.SplitMode == SplitByExact || e.SplitMode == SplitByPercentage
+}
+
+// Proportions returns the proportions of the shares based on the split mode, in the order of shares.
+// The amount of the expense should be divided proportionally to them.
+func (e *Expense) Proportions() []Amount {
+	proportions := make([]Amount, len(e.Shares))
+	for i, share := range e.Shares {
+		if e.UsesShareValues() {
+			proportions[i] = share.ShareValue
+		} else {
+			proportions[i] = AmountOf(int64(share.ShareWeight))
+		}
+	}
+	return proportions
+}
+
+// HasPaidPortions reports whether the amount is paid by the portions in the shares, instead of a single payer.
+func (e *Expense) HasPaidPortions() bool {
+	for _, share := range e.Shares {
+		if !share.Paid.IsZero() {
+			return true
+		}
+	}
+	return false
+}
+
+// PaidPortions returns the portion of the amount paid by each member, in the order of shares.
+// payerIndex is the index of the single payer and is only used if the expense has no paid portions.
+func (e *Expense) PaidPortions(payerIndex int) []Amount {
+	hasPaidPortions := e.HasPaidPortions()
+	portions := make([]Amount, len(e.Shares))
+	for i, share := range e.Shares {
+		if hasPaidPortions {
+			portions[i] = share.Paid
+		} else if i == payerIndex {
+			portions[i] = e.Amount
+		} else {
+			portions[i] = AmountZero()
+		}
+	}
+	return portions
+}
+
+// ValidatePaidPortions checks that the paid portions, if any, are non-negative and sum up to the amount.
+func (e *Expense) ValidatePaidPortions() error {
+	if !e.HasPaidPortions() {
+		return nil
+	}
+	sum := AmountZero()
+	for _, share := range e.Shares {
+		if share.Paid.IsNegative() {
+			return fmt.Errorf("paid amount of %q is negative", share.MemberName)
+		}
+		sum = sum.Add(share.Paid)
+	}
+	if !sum.Sub(e.Amount).IsZero() {
+		return fmt.Errorf("sum of paid amounts is %s, but should be %s", sum, e.Amount)
+	}
+	return nil
+}
+
+// ValidateShares checks that the shares match the split mode.
+func (e *Expense) ValidateShares() error {
+	switch e.SplitMode {
+	case SplitByExact, SplitByPercentage:
+		for _, share := range e.Shares {
+			if share.ShareValue.IsNegative() {
+				return fmt.Errorf("share of %q is negative", share.MemberName)
+			}
+		}
+		expected := e.Amount
+		if e.SplitMode == SplitByPercentage {
+			if e.Amount.IsZero() {
+				return nil
+			}
+			expected = AmountOf(100)
+		}
+		if sum := e.SumOfValues(); !sum.Sub(expected).IsZero() {
+			return fmt.Errorf("sum of %s shares is %s, but should be %s", e.SplitMode, sum, expected)
+		}
+	default:
+		if !e.Amount.IsZero() && e.SumOfWeights() == 0 {
+			return errors.New("sum of share weights is zero")
+		}
+	}
+	return nil
+}
+
+func ParseShareWeight(weightStr string) (int, error) {
+	weightStr = strings.TrimSpace(weightStr)
+
+	if weightStr == "" {
+		return 0, nil
+	}
+
+	if b, err := strconv.ParseBool(weightStr); err == nil {
+		var weight int
+		if b {
+			weight = 1
+		} else {
+			weight = 0
+		}
+		return weight, nil
+	}
+
+	return strconv.Atoi(weightStr)
+}
+
+// ParseShareValue parses the exact amount or the percentage of a share. A trailing percent sign is ignored.
+func ParseShareValue(valueStr string) (Amount, error) {
+	return ParseAmount(strings.TrimSuffix(strings.TrimSpace(valueStr), "%"))
+}
@@ -0,0 +1,80 @@
+package ledger_test
+
+import (
+	"github.com/MeysamBavi/group-expense-manager/pkg/ledger"
+	assert2 "github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestExpense_ValidateShares(t *testing.T) {
+	assert := assert2.New(t)
+
+	newExpense := func(amount int64, mode ledger.SplitMode, values ...string) *ledger.Expense {
+		expense := &ledger.Expense{Amount: ledger.AmountOf(amount), SplitMode: mode}
+		for _, value := range values {
+			share := ledger.Share{}
+			if expense.UsesShareValues() {
+				share.ShareValue, _ = ledger.ParseShareValue(value)
+			} else {
+				share.ShareWeight, _ = ledger.ParseShareWeight(value)
+			}
+			expense.Shares = append(expense.Shares, share)
+		}
+		return expense
+	}
+
+	assert.NoError(newExpense(10, ledger.SplitByWeight, "1", "true").ValidateShares())
+	assert.NoError(newExpense(0, ledger.SplitByWeight, "0", "0").ValidateShares())
+	assert.Error(newExpense(10, ledger.SplitByWeight, "0", "false").ValidateShares())
+
+	assert.NoError(newExpense(10, ledger.SplitByExact, "2.5", "7.5").ValidateShares())
+	assert.NoError(newExpense(0, ledger.SplitByExact, "", "").ValidateShares())
+	assert.Error(newExpense(10, ledger.SplitByExact, "2.5", "7").ValidateShares())
+	assert.Error(newExpense(10, ledger.SplitByExact, "-2", "12").ValidateShares())
+
+	assert.NoError(newExpense(10, ledger.SplitByPercentage, "33.3%", "66.7").ValidateShares())
+	assert.NoError(newExpense(0, ledger.SplitByPercentage, "10", "").ValidateShares())
+	assert.Error(newExpense(10, ledger.SplitByPercentage, "50", "49").ValidateShares())
+}
+
+func TestParseSplitMode(t *testing.T) {
+	assert := assert2.New(t)
+
+	for value, expected := range map[string]ledger.SplitMode{
+		"":         ledger.SplitByWeight,
+		" Weight ": ledger.SplitByWeight,
+		"EXACT":    ledger.SplitByExact,
+		"percent":  ledger.SplitByPercentage,
+	} {
+		mode, err := ledger.ParseSplitMode(value)
+		assert.NoError(err)
+		assert.Equal(expected, mode)
+	}
+
+	_, err := ledger.ParseSplitMode("shares")
+	assert.Error(err)
+}
+
+func TestExpense_PaidPortions(t *testing.T) {
+	assert := assert2.New(t)
+
+	expense := &ledger.Expense{
+		Amount: ledger.AmountOf(90),
+		Shares: []ledger.Share{{MemberName: "a"}, {MemberName: "b"}, {MemberName: "c"}},
+	}
+	assert.False(expense.HasPaidPortions())
+	assert.NoError(expense.ValidatePaidPortions())
+	assert.Equal([]string{"0", "90", "0"}, formatParts(expense.PaidPortions(1), 0))
+
+	expense.Shares[0].Paid = ledger.AmountOf(60)
+	expense.Shares[2].Paid = ledger.AmountOf(30)
+	assert.True(expense.HasPaidPortions())
+	assert.NoError(expense.ValidatePaidPortions())
+	assert.Equal([]string{"60", "0", "30"}, formatParts(expense.PaidPortions(1), 0))
+
+	expense.Shares[2].Paid = ledger.AmountOf(20)
+	assert.Error(expense.ValidatePaidPortions())
+	expense.Shares[1].Paid = ledger.AmountOf(-10)
+	expense.Shares[2].Paid = ledger.AmountOf(40)
+	assert.Error(expense.ValidatePaidPortions())
+}
@@ -0,0 +1,83 @@
+// Package ledger holds the domain logic of GEM: the members of a group, their expenses and transactions, and the
+// calculation of debts and settlements. It has no dependency on the spreadsheet format, so it can be used by any
+// front end.
+//
+// Debts are kept in a matrix where the cell at row i and column j is the amount that member i should pay member j.
+// Members are identified by their index in Ledger.Members; names are matched case-insensitively.
+package ledger
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Converter converts amounts of other currencies to the base currency of the group.
+type Converter interface {
+	Convert(amount Amount, currency string, t Time) (Amount, error)
+}
+
+// Ledger is the whole financial state of a group.
+type Ledger struct {
+	Members      []*Member
+	Expenses     []*Expense
+	Transactions []*Transaction
+	// BaseState is the debt matrix before the expenses and transactions. It can be nil.
+	BaseState [][]Amount
+	// FractionDigits is the number of digits after the decimal point of the smallest currency unit.
+	FractionDigits int
+	// Rounding is the policy used to round the shares of expenses. Empty means LargestRemainder.
+	Rounding RoundingPolicy
+	// Rates converts the amounts in other currencies. If it is nil, all amounts should be in the base currency.
+	Rates Converter
+}
+
+// IndexOf returns the index of the member with the given name, or -1 if there is no such member.
+func (l *Ledger) IndexOf(name string) int {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for i, member := range l.Members {
+		if strings.ToLower(strings.TrimSpace(member.Name)) == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func (l *Ledger) requireIndexOf(name string) (int, error) {
+	i := l.IndexOf(name)
+	if i == -1 {
+		return -1, fmt.Errorf("found no member with name %q", name)
+	}
+	return i, nil
+}
+
+func (l *Ledger) convert(amount Amount, currency string, t Time) (Amount, error) {
+	if l.Rates == nil {
+		if currency != "" {
+			return Amount{}, fmt.Errorf("found no exchange rate for %q", currency)
+		}
+		return amount, nil
+	}
+	return l.Rates.Convert(amount, currency, t)
+}
+
+// EmptyMatrix returns a square matrix of zero amounts.
+func EmptyMatrix(size int) [][]Amount {
+	result := make([][]Amount, size)
+	for i := range result {
+		result[i] = make([]Amount, size)
+		for j := range result[i] {
+			result[i][j] = AmountZero()
+		}
+	}
+	return result
+}
+
+// CopyMatrix returns a copy of a matrix.
+func CopyMatrix(source [][]Amount) [][]Amount {
+	result := make([][]Amount, len(source))
+	for i := range source {
+		result[i] = make([]Amount, len(source[i]))
+		copy(result[i], source[i])
+	}
+	return result
+}
@@ -0,0 +1,358 @@
+package ledger_test
+
+import (
+	"fmt"
+	"github.com/MeysamBavi/group-expense-manager/pkg/ledger"
+	assert2 "github.com/stretchr/testify/assert"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestComputeSettlements(t *testing.T) {
+	assert := assert2.New(t)
+
+	l := &ledger.Ledger{
+		Members: []*ledger.Member{{Name: "alice"}, {Name: "bob"}, {Name: "carol"}},
+		Expenses: []*ledger.Expense{
+			{
+				Title:     "dinner",
+				PayerName: "Alice",
+				Amount:    ledger.AmountOf(90),
+				Shares: []ledger.Share{
+					{MemberName: "alice", ShareWeight: 1},
+					{MemberName: "bob", ShareWeight: 1},
+					{MemberName: "carol", ShareWeight: 1},
+				},
+			},
+			{
+				Title:     "taxi",
+				PayerName: "bob",
+				Amount:    ledger.AmountOf(20),
+				SplitMode: ledger.SplitByExact,
+				Shares: []ledger.Share{
+					{MemberName: "bob", ShareValue: ledger.AmountOf(5)},
+					{MemberName: "carol", ShareValue: ledger.AmountOf(15)},
+				},
+			},
+		},
+		Transactions: []*ledger.Transaction{
+			{ReceiverName: "alice", PayerName: "carol", Amount: ledger.AmountOf(10)},
+		},
+	}
+
+	debtMatrix, err := ledger.ComputeDebtMatrix(l)
+	assert.NoError(err)
+	assert.Equal("30", debtMatrix[1][0].String())
+	assert.Equal("20", debtMatrix[2][0].String())
+	assert.Equal("15", debtMatrix[2][1].String())
+
+	balances := ledger.ComputeBalances(debtMatrix)
+	assert.Equal("-50", balances[0].String())
+	assert.Equal("15", balances[1].String())
+	assert.Equal("35", balances[2].String())
+
+	settlements := ledger.ComputeSettlements(l.Members, debtMatrix)
+	assert.Len(settlements, 2)
+	assert.Equal(&ledger.Transaction{ReceiverName: "alice", PayerName: "bob", Amount: ledger.AmountOf(15)}, settlements[0])
+	assert.Equal(&ledger.Transaction{ReceiverName: "alice", PayerName: "carol", Amount: ledger.AmountOf(35)}, settlements[1])
+
+	before, steps, err := ledger.ExplainSettlements(l.Members, debtMatrix, settlements)
+	assert.NoError(err)
+	assert.Equal(balances, before)
+	assert.Len(steps, 2)
+	assert.Equal("-35", steps[0].Balances[0].String())
+	assert.Equal("0", steps[0].Balances[1].String())
+	assert.Equal("35", steps[0].Balances[2].String())
+	for _, balance := range steps[1].Balances {
+		assert.True(balance.IsZero())
+	}
+
+	l.Expenses[0].Currency = "EUR"
+	_, err = ledger.ComputeDebtMatrix(l)
+	assert.Error(err)
+
+	l.Expenses[0].Currency = ""
+	l.Transactions[0].PayerName = "dave"
+	_, err = ledger.ComputeDebtMatrix(l)
+	assert.Error(err)
+}
+
+func TestComputeDebtMatrix_PayerWithoutShare(t *testing.T) {
+	assert := assert2.New(t)
+
+	l := &ledger.Ledger{
+		Members: []*ledger.Member{{Name: "alice"}, {Name: "bob"}, {Name: "carol"}},
+		Expenses: []*ledger.Expense{
+			{
+				Title:     "gift",
+				PayerName: "alice",
+				Amount:    ledger.AmountOf(50),
+				Shares: []ledger.Share{
+					{MemberName: "bob", ShareWeight: 1},
+					{MemberName: "carol", ShareWeight: 1},
+				},
+			},
+		},
+		Rounding: ledger.PayerAbsorbs,
+	}
+
+	shareAmounts, err := ledger.ComputeShareAmounts(l, l.Expenses[0])
+	assert.NoError(err)
+	assert.Equal([]string{"25", "25"}, []string{shareAmounts[0].String(), shareAmounts[1].String()})
+
+	debtMatrix, err := ledger.ComputeDebtMatrix(l)
+	assert.NoError(err)
+	assert.Equal("25", debtMatrix[1][0].String())
+	assert.Equal("25", debtMatrix[2][0].String())
+
+	statement, err := ledger.ComputeStatement(l, 0)
+	assert.NoError(err)
+	if assert.Len(statement, 1) {
+		assert.Equal("50", statement[0].Credit.String())
+	}
+}
+
+func TestComputeStatement(t *testing.T) {
+	assert := assert2.New(t)
+
+	l := &ledger.Ledger{
+		Members: []*ledger.Member{{Name: "alice"}, {Name: "bob"}, {Name: "carol"}},
+		Expenses: []*ledger.Expense{
+			{
+				Title:     "taxi",
+				Time:      ledger.TimeOfGregorian(time.Date(2023, time.May, 2, 0, 0, 0, 0, time.UTC)),
+				PayerName: "bob",
+				Amount:    ledger.AmountOf(20),
+				Shares: []ledger.Share{
+					{MemberName: "alice", ShareWeight: 1},
+					{MemberName: "bob", ShareWeight: 1},
+				},
+			},
+			{
+				Title:     "dinner",
+				Time:      ledger.TimeOfGregorian(time.Date(2023, time.May, 1, 0, 0, 0, 0, time.UTC)),
+				PayerName: "alice",
+				Amount:    ledger.AmountOf(90),
+				Shares: []ledger.Share{
+					{MemberName: "alice", ShareWeight: 1},
+					{MemberName: "bob", ShareWeight: 1},
+					{MemberName: "carol", ShareWeight: 1},
+				},
+			},
+		},
+		Transactions: []*ledger.Transaction{
+			{
+				Time:         ledger.TimeOfGregorian(time.Date(2023, time.May, 3, 0, 0, 0, 0, time.UTC)),
+				ReceiverName: "alice",
+				PayerName:    "carol",
+				Amount:       ledger.AmountOf(10),
+			},
+		},
+		BaseState: [][]ledger.Amount{
+			{ledger.AmountZero(), ledger.AmountZero(), ledger.AmountOf(5)},
+			{ledger.AmountZero(), ledger.AmountZero(), ledger.AmountZero()},
+			{ledger.AmountZero(), ledger.AmountZero(), ledger.AmountZero()},
+		},
+	}
+
+	entries, err := ledger.ComputeStatement(l, 0)
+	assert.NoError(err)
+	var descriptions, balances []string
+	for _, entry := range entries {
+		descriptions = append(descriptions, entry.Description)
+		balances = append(balances, entry.Balance.String())
+	}
+	assert.Equal([]string{"base state", "dinner", "taxi", "received from carol"}, descriptions)
+	assert.Equal([]string{"-5", "55", "45", "35"}, balances)
+	assert.Equal("90", entries[1].Credit.String())
+	assert.Equal("30", entries[1].Debit.String())
+
+	debtMatrix, err := ledger.ComputeDebtMatrix(l)
+	assert.NoError(err)
+	assert.Equal(ledger.ComputeBalances(debtMatrix)[0].Negative(), entries[len(entries)-1].Balance)
+}
+
+func TestComputeSettlementsByMode(t *testing.T) {
+	assert := assert2.New(t)
+
+	members := []*ledger.Member{{Name: "alice"}, {Name: "bob"}, {Name: "carol"}, {Name: "dave"}, {Name: "erin"}}
+	debtMatrix := ledger.EmptyMatrix(len(members))
+	debtMatrix[1][0] = ledger.AmountOf(2)
+	debtMatrix[2][3] = ledger.AmountOf(1)
+	debtMatrix[2][4] = ledger.AmountOf(2)
+
+	greedy, err := ledger.ComputeSettlementsByMode(members, debtMatrix, ledger.GreedySettlement)
+	assert.NoError(err)
+	assert.Len(greedy, 4)
+	optimal, err := ledger.ComputeSettlementsByMode(members, debtMatrix, ledger.OptimalSettlement)
+	assert.NoError(err)
+	assert.Len(optimal, 3)
+	assert.Contains(optimal, &ledger.Transaction{ReceiverName: "alice", PayerName: "bob", Amount: ledger.AmountOf(2)})
+
+	_, err = ledger.ComputeSettlementsByMode(members, debtMatrix, "fastest")
+	assert.Error(err)
+
+	// every mode should settle random groups, and the optimal mode should never need more transfers than the greedy one.
+	// The larger groups are beyond the exact search.
+	r := rand.New(rand.NewSource(1))
+	for _, size := range []int{2, 3, 5, 8, 12, 16, 17, 30} {
+		for round := 0; round < 10; round++ {
+			members = make([]*ledger.Member, size)
+			for i := range members {
+				members[i] = &ledger.Member{Name: fmt.Sprintf("member %d", i)}
+			}
+			debtMatrix = ledger.EmptyMatrix(size)
+			for i := 0; i < 2*size; i++ {
+				debtMatrix[r.Intn(size)][r.Intn(size)] = ledger.AmountOf(int64(r.Intn(10))).Divide(4)
+			}
+
+			greedy, err = ledger.ComputeSettlementsByMode(members, debtMatrix, ledger.GreedySettlement)
+			assert.NoError(err)
+			optimal, err = ledger.ComputeSettlementsByMode(members, debtMatrix, ledger.OptimalSettlement)
+			assert.NoError(err)
+			assert.LessOrEqual(len(optimal), len(greedy))
+			for _, mode := range ledger.SettlementModes {
+				settlements, err := ledger.ComputeSettlementsByMode(members, debtMatrix, mode)
+				assert.NoError(err)
+				before, steps, err := ledger.ExplainSettlements(members, debtMatrix, settlements)
+				assert.NoError(err)
+				balances := before
+				if len(steps) > 0 {
+					balances = steps[len(steps)-1].Balances
+				}
+				for _, balance := range balances {
+					assert.True(balance.IsZero(), "members of size %d are not settled: %v", size, balances)
+				}
+			}
+		}
+	}
+}
+
+func TestDebtSettlementModes(t *testing.T) {
+	assert := assert2.New(t)
+
+	members := []*ledger.Member{{Name: "alice"}, {Name: "bob"}, {Name: "carol"}, {Name: "dave"}}
+	debtMatrix := ledger.EmptyMatrix(len(members))
+	debtMatrix[0][1] = ledger.AmountOf(10)
+	debtMatrix[1][2] = ledger.AmountOf(10)
+	debtMatrix[2][0] = ledger.AmountOf(4)
+	debtMatrix[3][0] = ledger.AmountOf(6)
+
+	pairwise, err := ledger.ComputeSettlementsByMode(members, debtMatrix, ledger.PairwiseSettlement)
+	assert.NoError(err)
+	assert.Len(pairwise, 4)
+
+	// the cycle of alice, bob and carol is canceled out, and no one pays someone they do not owe. the greedy mode
+	// needs fewer transfers, but dave pays carol.
+	simplified, err := ledger.ComputeSettlementsByMode(members, debtMatrix, ledger.SimplifiedSettlement)
+	assert.NoError(err)
+	assert.Equal([]*ledger.Transaction{
+		{ReceiverName: "bob", PayerName: "alice", Amount: ledger.AmountOf(6)},
+		{ReceiverName: "carol", PayerName: "bob", Amount: ledger.AmountOf(6)},
+		{ReceiverName: "alice", PayerName: "dave", Amount: ledger.AmountOf(6)},
+	}, simplified)
+
+	_, steps, err := ledger.ExplainSettlements(members, debtMatrix, simplified)
+	assert.NoError(err)
+	for _, balance := range steps[len(steps)-1].Balances {
+		assert.True(balance.IsZero())
+	}
+
+	greedy, err := ledger.ComputeSettlementsByMode(members, debtMatrix, ledger.GreedySettlement)
+	assert.NoError(err)
+	assert.Equal([]*ledger.Transaction{{ReceiverName: "carol", PayerName: "dave", Amount: ledger.AmountOf(6)}}, greedy)
+}
+
+func TestComputeConstrainedSettlements(t *testing.T) {
+	assert := assert2.New(t)
+
+	members := []*ledger.Member{{Name: "alice"}, {Name: "bob"}, {Name: "carol"}, {Name: "dave"}}
+	debtMatrix := ledger.EmptyMatrix(len(members))
+	debtMatrix[1][0] = ledger.AmountOf(10)
+	debtMatrix[2][3] = ledger.AmountOf(10)
+
+	settlements, err := ledger.ComputeConstrainedSettlements(members, debtMatrix, ledger.GreedySettlement,
+		&ledger.SettlementConstraints{Blocked: []ledger.BlockedPair{{PayerName: "Carol", ReceiverName: "alice"}}})
+	assert.NoError(err)
+	assert.ElementsMatch([]*ledger.Transaction{
+		{ReceiverName: "alice", PayerName: "bob", Amount: ledger.AmountOf(10)},
+		{ReceiverName: "dave", PayerName: "carol", Amount: ledger.AmountOf(10)},
+	}, settlements)
+
+	settlements, err = ledger.ComputeConstrainedSettlements(members, debtMatrix, ledger.PairwiseSettlement,
+		&ledger.SettlementConstraints{Treasurer: "alice"})
+	assert.NoError(err)
+	assert.ElementsMatch([]*ledger.Transaction{
+		{ReceiverName: "alice", PayerName: "bob", Amount: ledger.AmountOf(10)},
+		{ReceiverName: "alice", PayerName: "carol", Amount: ledger.AmountOf(10)},
+		{ReceiverName: "dave", PayerName: "alice", Amount: ledger.AmountOf(10)},
+	}, settlements)
+
+	_, err = ledger.ComputeConstrainedSettlements(members, debtMatrix, ledger.GreedySettlement,
+		&ledger.SettlementConstraints{Blocked: []ledger.BlockedPair{
+			{PayerName: "carol", ReceiverName: "alice"},
+			{PayerName: "carol", ReceiverName: "dave"},
+		}})
+	assert.EqualError(err, "carol should pay 10, but can not pay any of the members who should receive money")
+
+	_, err = ledger.ComputeConstrainedSettlements(members, debtMatrix, ledger.PairwiseSettlement,
+		&ledger.SettlementConstraints{Blocked: []ledger.BlockedPair{{PayerName: "bob", ReceiverName: "alice"}}})
+	assert.Error(err)
+
+	_, err = ledger.ComputeConstrainedSettlements(members, debtMatrix, ledger.GreedySettlement,
+		&ledger.SettlementConstraints{MinimumAmount: ledger.AmountOf(15)})
+	assert.ErrorContains(err, "no other settlements are all at least the minimum")
+
+	settlements, err = ledger.ComputeConstrainedSettlements(members, debtMatrix, ledger.OptimalSettlement,
+		&ledger.SettlementConstraints{Preferred: []ledger.PreferredPair{{PayerName: "bob", ReceiverName: "Dave"}}})
+	assert.NoError(err)
+	assert.ElementsMatch([]*ledger.Transaction{
+		{ReceiverName: "dave", PayerName: "bob", Amount: ledger.AmountOf(10)},
+		{ReceiverName: "alice", PayerName: "carol", Amount: ledger.AmountOf(10)},
+	}, settlements)
+
+	// paying dave first would leave carol to pay alice, so the preference is not followed
+	settlements, err = ledger.ComputeConstrainedSettlements(members, debtMatrix, ledger.GreedySettlement,
+		&ledger.SettlementConstraints{
+			Blocked:   []ledger.BlockedPair{{PayerName: "carol", ReceiverName: "alice"}},
+			Preferred: []ledger.PreferredPair{{PayerName: "bob", ReceiverName: "dave"}},
+		})
+	assert.NoError(err)
+	assert.ElementsMatch([]*ledger.Transaction{
+		{ReceiverName: "alice", PayerName: "bob", Amount: ledger.AmountOf(10)},
+		{ReceiverName: "dave", PayerName: "carol", Amount: ledger.AmountOf(10)},
+	}, settlements)
+}
+
+func TestComputeConstrainedSettlements_MinimumAmount(t *testing.T) {
+	assert := assert2.New(t)
+
+	members := []*ledger.Member{{Name: "alice"}, {Name: "bob"}, {Name: "carol"}, {Name: "dave"}}
+	debtMatrix := ledger.EmptyMatrix(len(members))
+	debtMatrix[0][2] = ledger.AmountOf(2)
+	debtMatrix[3][2] = ledger.AmountOf(5)
+
+	// greedily, dave pays carol 5 and alice pays carol 2
+	greedy, err := ledger.ComputeSettlementsByMode(members, debtMatrix, ledger.GreedySettlement)
+	assert.NoError(err)
+	assert.Len(greedy, 2)
+
+	// the debt of dave is sent through alice instead
+	minimum := &ledger.SettlementConstraints{MinimumAmount: ledger.AmountOf(5)}
+	settlements, err := ledger.ComputeConstrainedSettlements(members, debtMatrix, ledger.GreedySettlement, minimum)
+	assert.NoError(err)
+	assert.ElementsMatch([]*ledger.Transaction{
+		{ReceiverName: "alice", PayerName: "dave", Amount: ledger.AmountOf(5)},
+		{ReceiverName: "carol", PayerName: "alice", Amount: ledger.AmountOf(7)},
+	}, settlements)
+
+	minimum.Blocked = []ledger.BlockedPair{{PayerName: "dave", ReceiverName: "alice"}}
+	_, err = ledger.ComputeConstrainedSettlements(members, debtMatrix, ledger.OptimalSettlement, minimum)
+	assert.EqualError(err, "alice should pay carol 2, which is less than the minimum settlement of 5, "+
+		"and no other settlements are all at least the minimum")
+
+	_, err = ledger.ComputeConstrainedSettlements(members, debtMatrix, ledger.GreedySettlement,
+		&ledger.SettlementConstraints{Treasurer: "carol", MinimumAmount: ledger.AmountOf(5)})
+	assert.EqualError(err, "alice should pay carol 2, which is less than the minimum settlement of 5; every debtor pays the treasurer all their debt")
+}
@@ -0,0 +1,37 @@
+package ledger
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	activeStatus   = "active"
+	inactiveStatus = "inactive"
+)
+
+// Member is a member of the group. A deactivated member keeps their history but takes no part in new expenses.
+type Member struct {
+	Name        string
+	CardNumber  string
+	Deactivated bool
+}
+
+func (m *Member) Status() string {
+	if m.Deactivated {
+		return inactiveStatus
+	}
+	return activeStatus
+}
+
+// ParseMemberStatus returns true if the status means the member is deactivated. Empty status is considered active.
+func ParseMemberStatus(status string) (bool, error) {
+	switch strings.ToLower(strings.TrimSpace(status)) {
+	case "", activeStatus:
+		return false, nil
+	case inactiveStatus:
+		return true, nil
+	default:
+		return false, fmt.Errorf("invalid member status %q; valid values are %q and %q", status, activeStatus, inactiveStatus)
+	}
+}
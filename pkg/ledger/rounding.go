@@ -0,0 +1,162 @@
+package ledger
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+)
+
+// RoundingPolicy specifies how an amount is split into parts that are whole multiples of the smallest currency unit.
+type RoundingPolicy string
+
+const (
+	// LargestRemainder rounds every part down and gives the remaining units to the parts with the largest remainders.
+	LargestRemainder RoundingPolicy = "largest-remainder"
+	// RoundHalfEven rounds every part to the nearest unit (ties to even) and then corrects the total by adjusting
+	// the parts with the largest rounding errors.
+	RoundHalfEven RoundingPolicy = "round-half-even"
+	// PayerAbsorbs rounds every part down, except the payer's part which absorbs the remainder.
+	PayerAbsorbs RoundingPolicy = "payer-absorbs"
+)
+
+var RoundingPolicies = []RoundingPolicy{LargestRemainder, RoundHalfEven, PayerAbsorbs}
+
+func ParseRoundingPolicy(value string) (RoundingPolicy, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	for _, policy := range RoundingPolicies {
+		if value == string(policy) {
+			return policy, nil
+		}
+	}
+	return "", fmt.Errorf("invalid rounding policy %q", value)
+}
+
+// Split divides the amount between the weights. Every part is a whole multiple of 10^-fractionDigits and the parts sum up
+// to the amount rounded to fractionDigits, the same way Format rounds it. Ties are broken in favor of the smaller index, so the result is deterministic.
+// payerIndex is only used by the PayerAbsorbs policy; if it is out of range LargestRemainder is used instead.
+// If the sum of weights is zero, all the parts are zero.
+func (a Amount) Split(weights []int, payerIndex, fractionDigits int, policy RoundingPolicy) []Amount {
+	intWeights := make([]*big.Int, len(weights))
+	for i, w := range weights {
+		intWeights[i] = big.NewInt(int64(w))
+	}
+	return a.split(intWeights, payerIndex, fractionDigits, policy)
+}
+
+// SplitByAmounts is like Split, but the weights can be fractional. The weights should be non-negative.
+func (a Amount) SplitByAmounts(weights []Amount, payerIndex, fractionDigits int, policy RoundingPolicy) []Amount {
+	commonDenominator := big.NewInt(1)
+	for _, w := range weights {
+		if w.r == nil {
+			continue
+		}
+		gcd := new(big.Int).GCD(nil, nil, commonDenominator, w.r.Denom())
+		commonDenominator.Mul(commonDenominator, new(big.Int).Quo(w.r.Denom(), gcd))
+	}
+
+	intWeights := make([]*big.Int, len(weights))
+	for i, w := range weights {
+		if w.r == nil {
+			intWeights[i] = new(big.Int)
+			continue
+		}
+		scaled := new(big.Rat).Mul(w.r, new(big.Rat).SetInt(commonDenominator))
+		intWeights[i] = new(big.Int).Set(scaled.Num())
+	}
+	return a.split(intWeights, payerIndex, fractionDigits, policy)
+}
+
+func (a Amount) split(weights []*big.Int, payerIndex, fractionDigits int, policy RoundingPolicy) []Amount {
+	parts := make([]Amount, len(weights))
+	sumOfWeights := new(big.Int)
+	for _, w := range weights {
+		sumOfWeights.Add(sumOfWeights, w)
+	}
+	if sumOfWeights.Sign() == 0 || a.IsZero() {
+		for i := range parts {
+			parts[i] = AmountZero()
+		}
+		return parts
+	}
+
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(fractionDigits)), nil)
+	negative := a.IsNegative()
+	total := roundHalfUp(new(big.Rat).Mul(new(big.Rat).Abs(a.r), new(big.Rat).SetInt(scale)))
+
+	denominator := sumOfWeights
+	units := make([]*big.Int, len(weights))
+	remainders := make([]*big.Int, len(weights))
+	sumOfUnits := new(big.Int)
+	for i, w := range weights {
+		units[i], remainders[i] = new(big.Int).DivMod(new(big.Int).Mul(total, w), denominator, new(big.Int))
+		sumOfUnits.Add(sumOfUnits, units[i])
+	}
+
+	if policy == PayerAbsorbs && (payerIndex < 0 || payerIndex >= len(weights)) {
+		policy = LargestRemainder
+	}
+
+	switch policy {
+	case PayerAbsorbs:
+		units[payerIndex].Add(units[payerIndex], new(big.Int).Sub(total, sumOfUnits))
+	case RoundHalfEven:
+		// errors[i] is (exact part - rounded part) * sumOfWeights
+		errors := make([]*big.Int, len(weights))
+		twice := new(big.Int)
+		for i := range units {
+			errors[i] = new(big.Int).Set(remainders[i])
+			c := twice.Lsh(remainders[i], 1).Cmp(denominator)
+			if c == 1 || (c == 0 && units[i].Bit(0) == 1) {
+				units[i].Add(units[i], big.NewInt(1))
+				sumOfUnits.Add(sumOfUnits, big.NewInt(1))
+				errors[i].Sub(errors[i], denominator)
+			}
+		}
+		left := new(big.Int).Sub(total, sumOfUnits).Int64()
+		if left > 0 {
+			distributeUnits(units, errors, left, 1)
+		} else if left < 0 {
+			negatedErrors := make([]*big.Int, len(errors))
+			for i := range errors {
+				negatedErrors[i] = new(big.Int).Neg(errors[i])
+			}
+			distributeUnits(units, negatedErrors, -left, -1)
+		}
+	default:
+		distributeUnits(units, remainders, new(big.Int).Sub(total, sumOfUnits).Int64(), 1)
+	}
+
+	for i := range parts {
+		r := new(big.Rat).SetFrac(units[i], scale)
+		if negative {
+			r.Neg(r)
+		}
+		parts[i] = Amount{r}
+	}
+	return parts
+}
+
+// distributeUnits adds delta to count units with the largest priorities. Ties are broken by the smaller index.
+func distributeUnits(units, priorities []*big.Int, count int64, delta int64) {
+	indices := make([]int, len(units))
+	for i := range indices {
+		indices[i] = i
+	}
+	sort.SliceStable(indices, func(i, j int) bool {
+		return priorities[indices[i]].Cmp(priorities[indices[j]]) == 1
+	})
+	for i := int64(0); i < count; i++ {
+		index := indices[i%int64(len(indices))]
+		units[index].Add(units[index], big.NewInt(delta))
+	}
+}
+
+// roundHalfUp rounds a non-negative number to the nearest integer, rounding halves up. It matches Amount.Format.
+func roundHalfUp(r *big.Rat) *big.Int {
+	quotient, remainder := new(big.Int).DivMod(r.Num(), r.Denom(), new(big.Int))
+	if new(big.Int).Lsh(remainder, 1).Cmp(r.Denom()) >= 0 {
+		quotient.Add(quotient, big.NewInt(1))
+	}
+	return quotient
+}
@@ -0,0 +1,105 @@
+package ledger_test
+
+import (
+	"github.com/MeysamBavi/group-expense-manager/pkg/ledger"
+	assert2 "github.com/stretchr/testify/assert"
+	"math/rand"
+	"testing"
+)
+
+func formatParts(parts []ledger.Amount, fractionDigits int) []string {
+	result := make([]string, len(parts))
+	for i, part := range parts {
+		result[i] = part.Format(fractionDigits)
+	}
+	return result
+}
+
+func TestAmount_Split(t *testing.T) {
+	assert := assert2.New(t)
+
+	amount := ledger.AmountOf(100)
+	assert.Equal([]string{"34", "33", "33"},
+		formatParts(amount.Split([]int{1, 1, 1}, 2, 0, ledger.LargestRemainder), 0))
+	assert.Equal([]string{"33", "33", "34"},
+		formatParts(amount.Split([]int{1, 1, 1}, 2, 0, ledger.PayerAbsorbs), 0))
+	assert.Equal([]string{"34", "33", "33"},
+		formatParts(amount.Split([]int{1, 1, 1}, 2, 0, ledger.RoundHalfEven), 0))
+
+	amount, _ = ledger.ParseAmount("10.01")
+	assert.Equal([]string{"3.34", "0.00", "6.67"},
+		formatParts(amount.Split([]int{1, 0, 2}, 0, 2, ledger.LargestRemainder), 2))
+	assert.Equal([]string{"3.34", "0.00", "6.67"},
+		formatParts(amount.Split([]int{1, 0, 2}, 0, 2, ledger.PayerAbsorbs), 2))
+
+	// 5 / 2 = 2.5 for both parts; round half even gives 2 to both and then corrects the first one
+	assert.Equal([]string{"3", "2"},
+		formatParts(ledger.AmountOf(5).Split([]int{1, 1}, 0, 0, ledger.RoundHalfEven), 0))
+	// 7 / 4 * 1 = 1.75 rounds up, 7 / 4 * 3 = 5.25 rounds down
+	assert.Equal([]string{"2", "5"},
+		formatParts(ledger.AmountOf(7).Split([]int{1, 3}, 0, 0, ledger.RoundHalfEven), 0))
+	assert.Equal([]string{"2", "5"},
+		formatParts(ledger.AmountOf(7).Split([]int{1, 3}, 0, 0, ledger.LargestRemainder), 0))
+	// 10 / 4 * 1 = 2.5 rounds to 2 and 10 / 4 * 3 = 7.5 rounds to 8
+	assert.Equal([]string{"2", "8"},
+		formatParts(ledger.AmountOf(10).Split([]int{1, 3}, 0, 0, ledger.RoundHalfEven), 0))
+
+	assert.Equal([]string{"-34", "-33", "-33"},
+		formatParts(ledger.AmountOf(-100).Split([]int{1, 1, 1}, 0, 0, ledger.LargestRemainder), 0))
+	assert.Equal([]string{"0", "0"},
+		formatParts(ledger.AmountOf(100).Split([]int{0, 0}, 0, 0, ledger.LargestRemainder), 0))
+}
+
+func TestAmount_SplitByAmounts(t *testing.T) {
+	assert := assert2.New(t)
+
+	parse := func(values ...string) []ledger.Amount {
+		result := make([]ledger.Amount, len(values))
+		for i, value := range values {
+			result[i], _ = ledger.ParseAmount(value)
+		}
+		return result
+	}
+
+	amount, _ := ledger.ParseAmount("90.5")
+	assert.Equal([]string{"10.25", "30.00", "50.25"},
+		formatParts(amount.SplitByAmounts(parse("10.25", "30", "50.25"), 0, 2, ledger.LargestRemainder), 2))
+	assert.Equal([]string{"3.33", "3.33", "3.34"},
+		formatParts(ledger.AmountOf(10).SplitByAmounts(parse("33.3", "33.3", "33.4"), 0, 2, ledger.LargestRemainder), 2))
+	assert.Equal([]string{"3", "7"},
+		formatParts(ledger.AmountOf(10).SplitByAmounts(parse("0.3", "0.7"), 0, 0, ledger.LargestRemainder), 0))
+	assert.Equal([]string{"0", "0"},
+		formatParts(ledger.AmountOf(10).SplitByAmounts([]ledger.Amount{{}, ledger.AmountZero()}, 0, 0, ledger.LargestRemainder), 0))
+}
+
+func TestAmount_SplitSumsUpToTotal(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	for i := 0; i < 2000; i++ {
+		fractionDigits := random.Intn(3)
+		amount := ledger.AmountOf(random.Int63n(2_000_000) - 1_000_000).Divide(random.Intn(1000) + 1)
+		weights := make([]int, random.Intn(8)+1)
+		for j := range weights {
+			weights[j] = random.Intn(4)
+		}
+		weights[random.Intn(len(weights))]++
+		payerIndex := random.Intn(len(weights))
+		expected, _ := ledger.ParseAmount(amount.Format(fractionDigits))
+
+		for _, policy := range ledger.RoundingPolicies {
+			parts := amount.Split(weights, payerIndex, fractionDigits, policy)
+			sum := ledger.AmountZero()
+			for j, part := range parts {
+				sum = sum.Add(part)
+				if rounded, _ := ledger.ParseAmount(part.Format(fractionDigits)); !rounded.Sub(part).IsZero() {
+					t.Fatalf("part %s is not a multiple of the smallest unit (policy %s)", part, policy)
+				}
+				if weights[j] == 0 && !part.IsZero() && !(policy == ledger.PayerAbsorbs && j == payerIndex) {
+					t.Fatalf("member with zero weight got %s (policy %s)", part, policy)
+				}
+			}
+			if sum.Format(fractionDigits) != expected.Format(fractionDigits) {
+				t.Fatalf("parts of %s with weights %v sum up to %s (policy %s)", amount, weights, sum, policy)
+			}
+		}
+	}
+}
@@ -0,0 +1,389 @@
+package ledger
+
+import (
+	"fmt"
+	"math/bits"
+	"sort"
+	"strings"
+)
+
+// SettlementMode specifies how the settlements of a debt matrix are chosen.
+type SettlementMode string
+
+const (
+	// GreedySettlement repeatedly pays the largest creditor from the largest debtor. It needs at most n-1 transfers for n
+	// members with a non-zero balance.
+	GreedySettlement SettlementMode = "greedy"
+	// OptimalSettlement splits the members into as many groups with zero-sum balances as possible and settles each group
+	// separately, which needs the minimum number of transfers. For large groups the split is only searched for small
+	// groups, so the result may not be the minimum, but it is never longer than GreedySettlement.
+	OptimalSettlement SettlementMode = "optimal"
+	// PairwiseSettlement pays every debt of the debt matrix as it is, so members only pay the members they owe.
+	PairwiseSettlement SettlementMode = "pairwise"
+	// SimplifiedSettlement cancels out the cycles of debts, like a member owing a second member who owes the first one
+	// through a third member, and then pays the remaining debts of the debt matrix. Like PairwiseSettlement, members only
+	// pay the members they owe.
+	SimplifiedSettlement SettlementMode = "simplified"
+)
+
+var SettlementModes = []SettlementMode{GreedySettlement, OptimalSettlement, PairwiseSettlement, SimplifiedSettlement}
+
+func ParseSettlementMode(value string) (SettlementMode, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if value == "" {
+		return GreedySettlement, nil
+	}
+	for _, mode := range SettlementModes {
+		if value == string(mode) {
+			return mode, nil
+		}
+	}
+	return "", fmt.Errorf("invalid settlement mode %q", value)
+}
+
+// optimalSettlementLimit is the maximum number of members with a non-zero balance for which OptimalSettlement searches
+// all the groups of members; the search takes 2^n steps.
+const optimalSettlementLimit = 16
+
+// ComputeSettlements returns a short list of transactions that, if made, clear all the debts of a debt matrix, using
+// GreedySettlement. The members are used for the names of the receivers and payers and must be in the order of the
+// matrix.
+func ComputeSettlements(members []*Member, debtMatrix [][]Amount) []*Transaction {
+	settlements, _ := ComputeSettlementsByMode(members, debtMatrix, GreedySettlement)
+	return settlements
+}
+
+// ComputeSettlementsByMode is like ComputeSettlements, but chooses the settlements by the given mode.
+// The settlements are sorted by amount.
+func ComputeSettlementsByMode(members []*Member, debtMatrix [][]Amount, mode SettlementMode) ([]*Transaction, error) {
+	balances := nonZeroBalances(members, debtMatrix)
+	var settlements []*Transaction
+	switch mode {
+	case GreedySettlement, "":
+		settlements = greedySettlements(balances)
+	case OptimalSettlement:
+		settlements = optimalSettlements(balances)
+	case PairwiseSettlement:
+		settlements = debtSettlements(members, debtMatrix, false)
+	case SimplifiedSettlement:
+		settlements = debtSettlements(members, debtMatrix, true)
+	default:
+		return nil, fmt.Errorf("invalid settlement mode %q", mode)
+	}
+
+	sort.SliceStable(settlements, func(i, j int) bool {
+		return settlements[i].Amount.LessThan(settlements[j].Amount)
+	})
+	return settlements, nil
+}
+
+type balance struct {
+	name   string
+	amount Amount // positive means debtor
+}
+
+// nonZeroBalances returns the balances of the members who owe or are owed money, in the order of the members.
+func nonZeroBalances(members []*Member, debtMatrix [][]Amount) []balance {
+	balances := make([]balance, 0, len(members))
+	memberBalances := ComputeBalances(debtMatrix)
+	for memberIndex, member := range members {
+		if !memberBalances[memberIndex].IsZero() {
+			balances = append(balances, balance{name: member.Name, amount: memberBalances[memberIndex]})
+		}
+	}
+	return balances
+}
+
+// greedySettlements settles balances that sum up to zero by paying the largest creditor from the largest debtor, until
+// everyone is settled up. The balances are not changed.
+func greedySettlements(members []balance) []*Transaction {
+	balances := make([]balance, len(members))
+	copy(balances, members)
+	sort.SliceStable(balances, func(i, j int) bool {
+		return balances[i].amount.LessThan(balances[j].amount)
+	})
+
+	settlements := make([]*Transaction, 0)
+	addSettlement := func(receiver, payer int, amount Amount) {
+		if amount.IsZero() {
+			return
+		}
+		if amount.LessThan(AmountZero()) {
+			receiver, payer = payer, receiver
+			amount = amount.Negative()
+		}
+		settlements = append(settlements, &Transaction{
+			ReceiverName: balances[receiver].name,
+			PayerName:    balances[payer].name,
+			Amount:       amount,
+		})
+		balances[payer].amount = balances[payer].amount.Sub(amount)
+		balances[receiver].amount = balances[receiver].amount.Add(amount)
+	}
+	lowest, highest := 0, len(balances)-1
+	for highest > lowest {
+		if balances[lowest].amount.IsZero() {
+			lowest++
+			continue
+		}
+		if balances[highest].amount.IsZero() {
+			highest--
+			continue
+		}
+
+		deficit := balances[highest].amount.Add(balances[lowest].amount)
+		if deficit.IsPositive() {
+			addSettlement(lowest, highest, balances[lowest].amount.Negative())
+		} else {
+			addSettlement(lowest, highest, balances[highest].amount)
+		}
+	}
+	return settlements
+}
+
+// optimalSettlements splits non-zero balances into groups that sum up to zero and settles each group greedily. A group
+// of k members needs k-1 transfers, so the more groups, the fewer transfers.
+func optimalSettlements(balances []balance) []*Transaction {
+	var groups [][]balance
+	if len(balances) <= optimalSettlementLimit {
+		groups = zeroSumGroups(balances)
+	} else {
+		groups = smallZeroSumGroups(balances)
+	}
+
+	var settlements []*Transaction
+	for _, group := range groups {
+		settlements = append(settlements, greedySettlements(group)...)
+	}
+	// the greedy algorithm may find zero-sum groups by chance, which the search of small groups could miss
+	if greedy := greedySettlements(balances); len(greedy) < len(settlements) {
+		return greedy
+	}
+	return settlements
+}
+
+// zeroSumGroups returns the largest number of groups of balances that each sum up to zero, by searching all the subsets
+// of balances. dp[mask] is the largest number of zero-sum groups that the members of mask can be split into, if the
+// members of mask sum up to zero.
+func zeroSumGroups(balances []balance) [][]balance {
+	n := len(balances)
+	sums := make([]Amount, 1<<n)
+	dp := make([]int, 1<<n)
+	sums[0] = AmountZero()
+	for mask := 1; mask < 1<<n; mask++ {
+		lowest := bits.TrailingZeros(uint(mask))
+		sums[mask] = sums[mask&(mask-1)].Add(balances[lowest].amount)
+		for i := 0; i < n; i++ {
+			if mask&(1<<i) != 0 && dp[mask^(1<<i)] > dp[mask] {
+				dp[mask] = dp[mask^(1<<i)]
+			}
+		}
+		if sums[mask].IsZero() {
+			dp[mask]++
+		}
+	}
+
+	// walk back from all the members, removing one member at a time without losing a group; a group ends wherever the
+	// remaining members sum up to zero.
+	var groups [][]balance
+	var group []balance
+	for mask := 1<<n - 1; mask != 0; {
+		groupsOfRest := dp[mask]
+		if sums[mask].IsZero() {
+			groupsOfRest--
+		}
+		for i := 0; i < n; i++ {
+			if mask&(1<<i) != 0 && dp[mask^(1<<i)] == groupsOfRest {
+				group = append(group, balances[i])
+				mask ^= 1 << i
+				break
+			}
+		}
+		if sums[mask].IsZero() {
+			groups = append(groups, group)
+			group = nil
+		}
+	}
+	return groups
+}
+
+// smallZeroSumGroups returns groups of balances that sum up to zero by finding the groups of two and then the groups of
+// three members. The remaining members form the last group.
+func smallZeroSumGroups(balances []balance) [][]balance {
+	used := make([]bool, len(balances))
+	var groups [][]balance
+	findGroups := func(size int) {
+		byAmount := make(map[string][]int)
+		for i := range balances {
+			if !used[i] {
+				byAmount[balances[i].amount.String()] = append(byAmount[balances[i].amount.String()], i)
+			}
+		}
+		// pick finds an unused member with the given amount that is not one of the excluded members.
+		pick := func(amount Amount, excluded ...int) int {
+			for _, k := range byAmount[amount.String()] {
+				if !used[k] && !containsIndex(excluded, k) {
+					return k
+				}
+			}
+			return -1
+		}
+		for i := range balances {
+			if used[i] {
+				continue
+			}
+			if size == 2 {
+				if k := pick(balances[i].amount.Negative(), i); k != -1 {
+					used[i], used[k] = true, true
+					groups = append(groups, []balance{balances[i], balances[k]})
+				}
+				continue
+			}
+			for j := i + 1; j < len(balances) && !used[i]; j++ {
+				if used[j] {
+					continue
+				}
+				if k := pick(balances[i].amount.Add(balances[j].amount).Negative(), i, j); k != -1 {
+					used[i], used[j], used[k] = true, true, true
+					groups = append(groups, []balance{balances[i], balances[j], balances[k]})
+				}
+			}
+		}
+	}
+	findGroups(2)
+	findGroups(3)
+
+	var rest []balance
+	for i := range balances {
+		if !used[i] {
+			rest = append(rest, balances[i])
+		}
+	}
+	if len(rest) > 0 {
+		groups = append(groups, rest)
+	}
+	return groups
+}
+
+// debtSettlements returns a settlement for every debt of a debt matrix, after canceling out the cycles of debts if
+// simplify is true. The debt matrix is not changed.
+func debtSettlements(members []*Member, debtMatrix [][]Amount, simplify bool) []*Transaction {
+	debts := CopyMatrix(debtMatrix)
+	netDebts(debts)
+	if simplify {
+		for cycle := findDebtCycle(debts); cycle != nil; cycle = findDebtCycle(debts) {
+			// every member of the cycle pays and receives the smallest debt of the cycle, so the balances do not change.
+			smallest := debts[cycle[len(cycle)-1]][cycle[0]]
+			for i := 0; i < len(cycle)-1; i++ {
+				if debts[cycle[i]][cycle[i+1]].LessThan(smallest) {
+					smallest = debts[cycle[i]][cycle[i+1]]
+				}
+			}
+			for i := range cycle {
+				payer, receiver := cycle[i], cycle[(i+1)%len(cycle)]
+				debts[payer][receiver] = debts[payer][receiver].Sub(smallest)
+			}
+		}
+	}
+
+	settlements := make([]*Transaction, 0)
+	for payer := range debts {
+		for receiver, debt := range debts[payer] {
+			if debt.IsPositive() {
+				settlements = append(settlements, &Transaction{
+					ReceiverName: members[receiver].Name,
+					PayerName:    members[payer].Name,
+					Amount:       debt,
+				})
+			}
+		}
+	}
+	return settlements
+}
+
+// findDebtCycle returns the members of a cycle of positive debts, where each member owes the next one and the last one
+// owes the first one, or nil if there is no cycle.
+func findDebtCycle(debts [][]Amount) []int {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	states := make([]int, len(debts))
+	var path []int
+	var visit func(member int) []int
+	visit = func(member int) []int {
+		states[member] = visiting
+		path = append(path, member)
+		for creditor, debt := range debts[member] {
+			if !debt.IsPositive() {
+				continue
+			}
+			switch states[creditor] {
+			case visiting:
+				for i := range path {
+					if path[i] == creditor {
+						return append([]int(nil), path[i:]...)
+					}
+				}
+			case unvisited:
+				if cycle := visit(creditor); cycle != nil {
+					return cycle
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		states[member] = visited
+		return nil
+	}
+	for member := range debts {
+		if states[member] == unvisited {
+			if cycle := visit(member); cycle != nil {
+				return cycle
+			}
+		}
+	}
+	return nil
+}
+
+func containsIndex(indices []int, index int) bool {
+	for _, i := range indices {
+		if i == index {
+			return true
+		}
+	}
+	return false
+}
+
+// SettlementStep is a settlement and the net balances of all the members after it, in the order of the members.
+// Positive balances mean debtor, as in ComputeBalances.
+type SettlementStep struct {
+	Settlement *Transaction
+	Balances   []Amount
+}
+
+// ExplainSettlements shows how a list of settlements clears the debts of a debt matrix. It returns the net balances of
+// the members before settling and the balances after each settlement, in the order of the settlements; if the
+// settlements are complete, all the balances of the last step are zero.
+// The members are used to find the receivers and payers and must be in the order of the matrix.
+func ExplainSettlements(members []*Member, debtMatrix [][]Amount, settlements []*Transaction) ([]Amount, []SettlementStep, error) {
+	l := &Ledger{Members: members}
+	before := ComputeBalances(debtMatrix)
+	balances := before
+	steps := make([]SettlementStep, 0, len(settlements))
+	for _, settlement := range settlements {
+		receiverIndex, err := l.requireIndexOf(settlement.ReceiverName)
+		if err != nil {
+			return nil, nil, err
+		}
+		payerIndex, err := l.requireIndexOf(settlement.PayerName)
+		if err != nil {
+			return nil, nil, err
+		}
+		balances = append([]Amount(nil), balances...)
+		balances[payerIndex] = balances[payerIndex].Sub(settlement.Amount)
+		balances[receiverIndex] = balances[receiverIndex].Add(settlement.Amount)
+		steps = append(steps, SettlementStep{Settlement: settlement, Balances: balances})
+	}
+	return before, steps, nil
+}
@@ -0,0 +1,126 @@
+package ledger
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// EntryKind is the kind of the record behind an entry of a statement.
+type EntryKind string
+
+const (
+	BaseStateEntry   EntryKind = "base state"
+	ExpenseEntry     EntryKind = "expense"
+	TransactionEntry EntryKind = "transaction"
+)
+
+// StatementEntry is a record that changes the balance of a member. The amounts are in the base currency.
+type StatementEntry struct {
+	Kind EntryKind
+	// Time is the time of the expense or the transaction. It is nil for the base state.
+	Time        Time
+	Description string
+	// Credit is what the others owe the member because of the record, like the part of an expense that the member paid
+	// for the others or the money that they sent.
+	Credit Amount
+	// Debit is what the member owes the others because of the record, like their share of an expense or the money that
+	// they received.
+	Debit Amount
+	// Balance is the net balance of the member after the record. Positive means the others owe the member.
+	Balance Amount
+}
+
+// ComputeStatement returns the entries that change the balance of a member: the base state, every expense that the
+// member paid or had a non-zero share in, and every transaction that they sent or received. The expenses and the
+// transactions are sorted by time, and the records without a time come first.
+// The balance of the last entry is the net balance of the member in the debt matrix, with the opposite sign of
+// ComputeBalances.
+func ComputeStatement(l *Ledger, memberIndex int) ([]StatementEntry, error) {
+	if memberIndex < 0 || memberIndex >= len(l.Members) {
+		return nil, fmt.Errorf("member index %d is out of range", memberIndex)
+	}
+
+	var entries []StatementEntry
+	if l.BaseState != nil {
+		if len(l.BaseState) != len(l.Members) {
+			return nil, fmt.Errorf("base state has %d rows but there are %d members", len(l.BaseState), len(l.Members))
+		}
+		credit, debit := AmountZero(), AmountZero()
+		for i := range l.BaseState {
+			if i != memberIndex {
+				credit = credit.Add(l.BaseState[i][memberIndex])
+				debit = debit.Add(l.BaseState[memberIndex][i])
+			}
+		}
+		if !credit.IsZero() || !debit.IsZero() {
+			entries = append(entries, StatementEntry{Kind: BaseStateEntry, Description: "base state", Credit: credit, Debit: debit})
+		}
+	}
+
+	var records []StatementEntry
+	for _, expense := range l.Expenses {
+		memberIndices, debts, err := l.expenseDebts(expense)
+		if err != nil {
+			return nil, fmt.Errorf("expense %q: %w", expense.Title, err)
+		}
+		credit, debit := AmountZero(), AmountZero()
+		for i := range debts {
+			for j, debt := range debts[i] {
+				if memberIndices[j] == memberIndex {
+					credit = credit.Add(debt)
+				}
+				if memberIndices[i] == memberIndex {
+					debit = debit.Add(debt)
+				}
+			}
+		}
+		if !credit.IsZero() || !debit.IsZero() {
+			records = append(records, StatementEntry{
+				Kind:        ExpenseEntry,
+				Time:        expense.Time,
+				Description: expense.Title,
+				Credit:      credit,
+				Debit:       debit,
+			})
+		}
+	}
+
+	for _, transaction := range l.Transactions {
+		receiverIndex, payerIndex, amount, err := l.transactionDebt(transaction)
+		if err != nil {
+			return nil, err
+		}
+		entry := StatementEntry{Kind: TransactionEntry, Time: transaction.Time, Credit: AmountZero(), Debit: AmountZero()}
+		switch memberIndex {
+		case payerIndex:
+			entry.Description = "paid " + l.Members[receiverIndex].Name
+			entry.Credit = amount
+		case receiverIndex:
+			entry.Description = "received from " + l.Members[payerIndex].Name
+			entry.Debit = amount
+		default:
+			continue
+		}
+		records = append(records, entry)
+	}
+
+	sort.SliceStable(records, func(i, j int) bool {
+		return gregorianOf(records[i].Time).Before(gregorianOf(records[j].Time))
+	})
+	entries = append(entries, records...)
+
+	balance := AmountZero()
+	for i := range entries {
+		balance = balance.Add(entries[i].Credit).Sub(entries[i].Debit)
+		entries[i].Balance = balance
+	}
+	return entries, nil
+}
+
+func gregorianOf(t Time) time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+	return t.ToGregorian()
+}
@@ -1,4 +1,4 @@
-package model
+package ledger
 
 import (
 	"fmt"
@@ -30,10 +30,15 @@ var layouts = [...]string{
 	"2/1/06",
 }
 
+// Time is the time of an expense or a transaction, in gregorian or persian calendar.
 type Time interface {
 	fmt.Stringer
+	// ToGregorian returns the time in gregorian calendar. The result is zero if the time is not specified.
+	ToGregorian() time.Time
 }
 
+// ParseTime parses a time in one of the supported layouts. Dates from the year 2000 on are gregorian and the rest are
+// persian. An empty value is an unspecified time.
 func ParseTime(value string) (Time, error) {
 	g, errG := parseGregorian(value)
 	if errG == nil {
@@ -67,6 +72,13 @@ func (g *gregorian) String() string {
 	return g.Format("2006/01/02 15:04")
 }
 
+func (g *gregorian) ToGregorian() time.Time {
+	if g == nil {
+		return time.Time{}
+	}
+	return g.Time
+}
+
 func parseGregorian(value string) (*gregorian, error) {
 	value = strings.TrimSpace(value)
 	if value == "" {
@@ -84,6 +96,7 @@ func parseGregorian(value string) (*gregorian, error) {
 	return nil, fmt.Errorf("cannot not parse %q as gregorian date: %w", value, firstError)
 }
 
+// TimeOfGregorian returns a gregorian time. The zero time is an unspecified time.
 func TimeOfGregorian(t time.Time) Time {
 	return &gregorian{t}
 }
@@ -96,6 +109,10 @@ func (p *persian) String() string {
 	return p.Format("yyyy/MM/dd HH:mm")
 }
 
+func (p *persian) ToGregorian() time.Time {
+	return p.Time.Time()
+}
+
 const (
 	dayRE    = "(?P<day>\\d{1,2})"
 	monthRE  = "(?P<month>\\d{1,2})"
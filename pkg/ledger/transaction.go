@@ -0,0 +1,11 @@
+package ledger
+
+// Transaction is an amount of money paid by a member to another member.
+type Transaction struct {
+	ReceiverName string
+	PayerName    string
+	Amount       Amount
+	// Currency of the amount. Empty means the base currency of the group.
+	Currency string
+	Time     Time
+}